@@ -3,14 +3,18 @@ module github.com/be-tech/version-manager
 go 1.22.2
 
 require (
-	github.com/AlecAivazis/survey/v2 v2.3.7 // indirect
-	github.com/Masterminds/semver/v3 v3.3.1 // indirect
-	github.com/briandowns/spinner v1.23.2 // indirect
-	github.com/fatih/color v1.18.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/AlecAivazis/survey/v2 v2.3.7
+	github.com/Masterminds/semver/v3 v3.3.1
+	github.com/briandowns/spinner v1.23.2
+	github.com/fatih/color v1.18.0
+	github.com/joho/godotenv v1.5.1
+	github.com/mattn/go-isatty v0.0.20
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
 	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/term v0.1.0 // indirect
@@ -0,0 +1,546 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/version"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	CommandPromote = "promote"
+	CommandRelease = "release"
+	CommandVerify  = "verify"
+	CommandHelp    = "help"
+)
+
+// ReleasePublish is the "release" subcommand that publishes a draft release.
+const ReleasePublish = "publish"
+
+var validBumps = append(append([]string{}, version.BumpTypes...), "none")
+
+// releaseAuto creates the release on the provider detected from the remote URL.
+const releaseAuto = "auto"
+
+var validReleaseProviders = append(append([]string{}, config.ReleaseProviders...), releaseAuto, "none")
+
+// Command is the result of parsing the command line: which subcommand to run,
+// which config values were given explicitly and whether prompts may be shown.
+type Command struct {
+	Name        string
+	Args        []string
+	Provided    map[string]bool
+	Interactive bool
+
+	// LogLevel and LogFormat select the logger, from --quiet, --verbose and
+	// --log-format.
+	LogLevel  utils.Level
+	LogFormat string
+}
+
+// Parse reads args (without the program name) into cfg. Running without a
+// subcommand is the same as running "promote" with no flags.
+func Parse(args []string, cfg *config.Config) (*Command, error) {
+	cmd := &Command{
+		Name:        CommandPromote,
+		Provided:    make(map[string]bool),
+		Interactive: isInteractive(),
+		LogLevel:    utils.LevelInfo,
+		LogFormat:   utils.LogFormatText,
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd.Name = args[0]
+		args = args[1:]
+	}
+
+	switch cmd.Name {
+	case CommandPromote:
+		return cmd, parsePromote(cmd, args, cfg)
+	case CommandRelease:
+		return cmd, parseRelease(cmd, args, cfg)
+	case CommandVerify:
+		return cmd, parseVerify(cmd, args, cfg)
+	case CommandHelp:
+		Usage(os.Stdout)
+		return cmd, nil
+	default:
+		return nil, fmt.Errorf("comando desconhecido: %s (use \"git-manager help\")", cmd.Name)
+	}
+}
+
+func parsePromote(cmd *Command, args []string, cfg *config.Config) error {
+	fs := flag.NewFlagSet(CommandPromote, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	logging := addLogFlags(fs)
+
+	remote := fs.String("remote", "", "repositório remoto (ex.: origin)")
+	from := fs.String("from", "", "branch de origem")
+	to := fs.String("to", "", "branch de destino")
+	push := fs.Bool("push", false, "enviar as alterações para o remoto")
+	removeBranch := fs.Bool("remove-branch", false, "remover a branch de origem após o merge")
+	deleteRemote := fs.Bool("delete-remote", false, "remover também a branch de origem no remoto")
+	bump := fs.String("bump", "", "tipo de versão: "+strings.Join(validBumps, ", "))
+	releaseProvider := fs.String("release", "", "criar release: "+strings.Join(validReleaseProviders, ", "))
+	releaseTitle := fs.String("release-title", "", "título da release")
+	releaseNotes := fs.String("release-notes", "", "notas da release")
+	nonInteractive := fs.Bool("non-interactive", false, "nunca exibir perguntas, mesmo em um terminal")
+	var assets stringList
+	fs.Var(&assets, "asset", "arquivo ou padrão glob a anexar à release (pode ser repetido)")
+	releaseExisting := fs.String("release-existing", "", "se a tag já tiver release: "+strings.Join(config.ExistingReleasePolicies, ", "))
+	draft := fs.Bool("draft", false, "criar a release como rascunho")
+	makeLatest := fs.String("make-latest", "", "make_latest do GitHub: "+strings.Join(config.MakeLatestValues, ", "))
+	releasedAt := fs.String("released-at", "", "released_at do GitLab, no formato RFC 3339")
+	mergeStrategy := fs.String("merge-strategy", "", "estratégia de merge: "+strings.Join(config.MergeStrategies, ", "))
+	mergeMessage := fs.String("merge-message", "", "template da mensagem do commit de merge")
+	onConflict := fs.String("on-conflict", "", "em conflitos de merge: "+strings.Join(config.ConflictActions, ", "))
+	sign := fs.Bool("sign", false, "assinar a tag da versão (GPG ou SSH)")
+	overridePolicy := fs.Bool("override-policy", false, "executar mesmo violando a política de promoção (registrado no log)")
+	pullRequest := fs.Bool("pull-request", false, "abrir um pull request / merge request em vez de fazer o merge local")
+	autoMerge := fs.Bool("auto-merge", false, "habilitar o auto-merge do pull request")
+	wait := fs.Bool("wait", false, "aguardar o merge do pull request para criar a tag e a release")
+	waitTimeout := fs.Duration("wait-timeout", 0, "tempo máximo de espera pelo merge do pull request (ex.: 30m)")
+	changelogFile := fs.String("changelog", "", "arquivo de changelog a atualizar no commit da release (ex.: CHANGELOG.md)")
+	dryRun := fs.Bool("dry-run", false, "mostrar os comandos git e requisições de API sem executá-los")
+	configFile := fs.String("config", "", "arquivo de configuração do repositório (padrão: "+config.RepoFileName+")")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v (use \"git-manager help\")", err)
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("argumento inesperado: %s", fs.Arg(0))
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		cmd.Provided[f.Name] = true
+	})
+
+	if err := logging.apply(cmd); err != nil {
+		return err
+	}
+
+	if *nonInteractive {
+		cmd.Interactive = false
+	}
+
+	if err := loadDefaults(cmd, cfg, *configFile); err != nil {
+		return err
+	}
+
+	if cmd.Provided["remote"] {
+		cfg.Remote = *remote
+	}
+	if cmd.Provided["from"] {
+		cfg.SourceBranch = *from
+	}
+	if cmd.Provided["to"] {
+		cfg.DestinationBranch = *to
+	}
+	if cmd.Provided["push"] {
+		cfg.Push = *push
+	}
+	if cmd.Provided["remove-branch"] {
+		cfg.RemoveBranch = *removeBranch
+	}
+	if cmd.Provided["delete-remote"] {
+		cfg.DeleteRemoteBranch = *deleteRemote
+	}
+
+	cfg.DryRun = *dryRun
+
+	if cmd.Provided["bump"] {
+		if !contains(validBumps, *bump) {
+			return fmt.Errorf("valor inválido para --bump: %s (esperado: %s)", *bump, strings.Join(validBumps, ", "))
+		}
+		if *bump != "none" {
+			cfg.Tag = *bump
+		}
+	}
+
+	if cmd.Provided["release"] {
+		provider := strings.ToLower(*releaseProvider)
+		if !contains(validReleaseProviders, provider) {
+			return fmt.Errorf("valor inválido para --release: %s (esperado: %s)", *releaseProvider, strings.Join(validReleaseProviders, ", "))
+		}
+		cfg.CreateRelease = provider != "none"
+		if cfg.CreateRelease && provider != releaseAuto {
+			cfg.RepoType = provider
+		}
+	}
+
+	if cmd.Provided["release-title"] {
+		cfg.ReleaseTitle = *releaseTitle
+	}
+	if cmd.Provided["release-notes"] {
+		cfg.ReleaseNotes = *releaseNotes
+	}
+	if cmd.Provided["changelog"] {
+		cfg.ChangelogFile = *changelogFile
+	}
+	// The flags apply to this run, whatever the rules of the destination say.
+	if cmd.Provided["merge-strategy"] {
+		if !contains(config.MergeStrategies, *mergeStrategy) {
+			return fmt.Errorf("valor inválido para --merge-strategy: %s (esperado: %s)", *mergeStrategy, strings.Join(config.MergeStrategies, ", "))
+		}
+		cfg.Merge.Strategy = *mergeStrategy
+		for i := range cfg.Merge.Rules {
+			cfg.Merge.Rules[i].Strategy = ""
+		}
+	}
+	if cmd.Provided["merge-message"] {
+		if _, err := config.ParseMergeMessage(*mergeMessage); err != nil {
+			return fmt.Errorf("valor inválido para --merge-message: %v", err)
+		}
+		cfg.Merge.Message = *mergeMessage
+		for i := range cfg.Merge.Rules {
+			cfg.Merge.Rules[i].Message = ""
+		}
+	}
+	if cmd.Provided["on-conflict"] {
+		if !contains(config.ConflictActions, *onConflict) {
+			return fmt.Errorf("valor inválido para --on-conflict: %s (esperado: %s)", *onConflict, strings.Join(config.ConflictActions, ", "))
+		}
+		cfg.Merge.OnConflict = *onConflict
+	}
+	if cmd.Provided["sign"] {
+		cfg.Signing.Tags = *sign
+	}
+	if cmd.Provided["override-policy"] {
+		cfg.Policy.Override = *overridePolicy
+	}
+	if cmd.Provided["pull-request"] {
+		cfg.PullRequest.Enabled = *pullRequest
+	}
+	if cmd.Provided["auto-merge"] {
+		cfg.PullRequest.AutoMerge = *autoMerge
+	}
+	if cmd.Provided["wait"] {
+		cfg.PullRequest.Wait = *wait
+	}
+	if cmd.Provided["wait-timeout"] {
+		if *waitTimeout <= 0 {
+			return fmt.Errorf("valor inválido para --wait-timeout: %s (esperado uma duração positiva, ex.: 30m)", *waitTimeout)
+		}
+		cfg.PullRequest.Timeout = *waitTimeout
+	}
+	if cmd.Provided["asset"] {
+		cfg.ReleaseAssets = assets
+	}
+	if cmd.Provided["release-existing"] {
+		if !contains(config.ExistingReleasePolicies, *releaseExisting) {
+			return fmt.Errorf("valor inválido para --release-existing: %s (esperado: %s)", *releaseExisting, strings.Join(config.ExistingReleasePolicies, ", "))
+		}
+		cfg.ReleaseExisting = *releaseExisting
+	}
+	if cmd.Provided["draft"] {
+		cfg.ReleaseDraft = *draft
+	}
+	if cmd.Provided["make-latest"] {
+		if err := setMakeLatest(cfg, *makeLatest); err != nil {
+			return err
+		}
+	}
+	if cmd.Provided["released-at"] {
+		at, err := time.Parse(time.RFC3339, *releasedAt)
+		if err != nil {
+			return fmt.Errorf("valor inválido para --released-at: %s (esperado RFC 3339, ex.: 2024-05-01T12:00:00Z)", *releasedAt)
+		}
+		cfg.ReleasedAt = at
+	}
+
+	if cfg.SourceBranch != "" && cfg.SourceBranch == cfg.DestinationBranch {
+		return fmt.Errorf("as branches de origem e destino devem ser diferentes: %s", cfg.SourceBranch)
+	}
+
+	return nil
+}
+
+// parseRelease reads "release publish <tag>", which publishes the draft
+// release of tag on the provider given by --release or detected from the
+// remote.
+func parseRelease(cmd *Command, args []string, cfg *config.Config) error {
+	if len(args) == 0 || args[0] != ReleasePublish {
+		return fmt.Errorf("uso: git-manager release publish <tag> (use \"git-manager help\")")
+	}
+
+	fs := flag.NewFlagSet(CommandRelease, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	logging := addLogFlags(fs)
+
+	remote := fs.String("remote", "origin", "repositório remoto")
+	releaseProvider := fs.String("release", "", "provedor: "+strings.Join(config.ReleaseProviders, ", "))
+	makeLatest := fs.String("make-latest", "", "make_latest do GitHub: "+strings.Join(config.MakeLatestValues, ", "))
+	configFile := fs.String("config", "", "arquivo de configuração do repositório (padrão: "+config.RepoFileName+")")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%v (use \"git-manager help\")", err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("uso: git-manager release publish <tag> (use \"git-manager help\")")
+	}
+	cmd.Args = []string{ReleasePublish, fs.Arg(0)}
+
+	fs.Visit(func(f *flag.Flag) {
+		cmd.Provided[f.Name] = true
+	})
+
+	if err := logging.apply(cmd); err != nil {
+		return err
+	}
+
+	if err := loadDefaults(cmd, cfg, *configFile); err != nil {
+		return err
+	}
+
+	if cmd.Provided["remote"] || cfg.Remote == "" {
+		cfg.Remote = *remote
+	}
+	if cmd.Provided["release"] {
+		provider := strings.ToLower(*releaseProvider)
+		if !contains(config.ReleaseProviders, provider) {
+			return fmt.Errorf("valor inválido para --release: %s (esperado: %s)", *releaseProvider, strings.Join(config.ReleaseProviders, ", "))
+		}
+		cfg.RepoType = provider
+	}
+	if cmd.Provided["make-latest"] {
+		if err := setMakeLatest(cfg, *makeLatest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// logFlags select the verbosity and format of the log, for every subcommand.
+type logFlags struct {
+	quiet   *bool
+	verbose *bool
+	format  *string
+}
+
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		quiet:   fs.Bool("quiet", false, "exibir apenas avisos e erros"),
+		verbose: fs.Bool("verbose", false, "exibir mensagens de depuração, como cada comando executado"),
+		format:  fs.String("log-format", utils.LogFormatText, "formato do log: "+strings.Join(utils.LogFormats, ", ")),
+	}
+}
+
+func (f *logFlags) apply(cmd *Command) error {
+	if *f.quiet && *f.verbose {
+		return fmt.Errorf("use --quiet ou --verbose, não os dois")
+	}
+	if !contains(utils.LogFormats, *f.format) {
+		return fmt.Errorf("valor inválido para --log-format: %s (esperado: %s)", *f.format, strings.Join(utils.LogFormats, ", "))
+	}
+
+	cmd.LogFormat = *f.format
+	switch {
+	case *f.quiet:
+		cmd.LogLevel = utils.LevelWarn
+	case *f.verbose:
+		cmd.LogLevel = utils.LevelDebug
+	}
+	return nil
+}
+
+// parseVerify reads "verify [<from>..<to>]", which checks the signatures of
+// the version tags after from up to and including to, or of a single tag. A
+// missing bound is open.
+func parseVerify(cmd *Command, args []string, cfg *config.Config) error {
+	fs := flag.NewFlagSet(CommandVerify, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	logging := addLogFlags(fs)
+
+	allowedSigners := fs.String("allowed-signers", "", "arquivo allowed signers das chaves SSH permitidas")
+	var allowedKeys stringList
+	fs.Var(&allowedKeys, "allowed-key", "fingerprint ou ID de chave GPG permitida (pode ser repetido)")
+	configFile := fs.String("config", "", "arquivo de configuração do repositório (padrão: "+config.RepoFileName+")")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v (use \"git-manager help\")", err)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("uso: git-manager verify [<de>..<até>] (use \"git-manager help\")")
+	}
+
+	// Args is the range as [from, to], or a single tag.
+	cmd.Args = []string{"", ""}
+	if fs.NArg() == 1 {
+		if from, to, ok := strings.Cut(fs.Arg(0), ".."); ok {
+			cmd.Args = []string{from, to}
+		} else {
+			cmd.Args = []string{fs.Arg(0)}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		cmd.Provided[f.Name] = true
+	})
+
+	if err := logging.apply(cmd); err != nil {
+		return err
+	}
+
+	if err := loadDefaults(cmd, cfg, *configFile); err != nil {
+		return err
+	}
+
+	if cmd.Provided["allowed-signers"] {
+		cfg.Signing.AllowedSigners = *allowedSigners
+	}
+	if cmd.Provided["allowed-key"] {
+		cfg.Signing.AllowedKeys = allowedKeys
+	}
+
+	return nil
+}
+
+func setMakeLatest(cfg *config.Config, value string) error {
+	if !contains(config.MakeLatestValues, value) {
+		return fmt.Errorf("valor inválido para --make-latest: %s (esperado: %s)", value, strings.Join(config.MakeLatestValues, ", "))
+	}
+	cfg.ReleaseMakeLatest = value
+	return nil
+}
+
+// loadDefaults applies, from lowest to highest precedence, the user file, the
+// repository file and the environment. Flags are applied afterwards by the caller.
+func loadDefaults(cmd *Command, cfg *config.Config, repoFile string) error {
+	files := []string{config.UserFilePath()}
+
+	if repoFile == "" {
+		if dir, err := os.Getwd(); err == nil {
+			repoFile = config.FindRepoFile(dir)
+		}
+	} else if _, err := os.Stat(repoFile); err != nil {
+		return fmt.Errorf("arquivo de configuração não encontrado: %s", repoFile)
+	}
+	files = append(files, repoFile)
+
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+
+		keys, err := cfg.LoadFile(file)
+		if err != nil {
+			return err
+		}
+		markProvided(cmd, keys)
+	}
+
+	keys, err := cfg.LoadEnv()
+	if err != nil {
+		return err
+	}
+	markProvided(cmd, keys)
+
+	return nil
+}
+
+func markProvided(cmd *Command, keys []string) {
+	for _, key := range keys {
+		if key == config.KeyRemote {
+			cmd.Provided["remote"] = true
+		}
+	}
+}
+
+// Usage writes the command line help to w.
+func Usage(w io.Writer) {
+	fmt.Fprint(w, `Uso:
+  git-manager [promote] [flags]
+  git-manager release publish <tag> [--remote <nome>] [--release <provedor>] [--make-latest <valor>]
+  git-manager verify [<de>..<até>] [--allowed-signers <arquivo>] [--allowed-key <chave>]
+  git-manager help
+
+Sem flags, todas as opções são perguntadas de forma interativa.
+Perguntas só aparecem para valores que não foram informados.
+
+Flags do comando promote:
+  --remote <nome>          repositório remoto (ex.: origin)
+  --from <branch>          branch de origem
+  --to <branch>            branch de destino
+  --push                   enviar as alterações para o remoto
+  --remove-branch          remover a branch de origem após o merge, se ela estiver mergeada no destino
+  --delete-remote          com --remove-branch, remover a branch também no remoto (pela API do
+                           GitHub/GitLab quando ela for protegida)
+  --bump <tipo>            major, minor, patch, premajor, preminor, prepatch, prerelease, auto ou none
+                           (auto infere a versão pelos Conventional Commits)
+  --release <provedor>     github, gitlab, gitea, forgejo, bitbucket,
+                           bitbucket-server, auto (detectado pela URL do remoto) ou none
+  --release-title <texto>  título da release
+  --release-notes <texto>  notas da release
+  --asset <padrão>         anexar à release os arquivos do padrão glob, com um SHA256SUMS
+                           (pode ser repetido)
+  --release-existing <p>   se a tag já tiver release: fail (padrão), update ou skip
+  --draft                  criar a release como rascunho (publique com "release publish")
+  --make-latest <valor>    GitHub: true, false ou legacy
+  --released-at <data>     GitLab: data da release em RFC 3339 (futura = release agendada)
+  --merge-strategy <e>     merge na branch de destino: merge (padrão), ff-only, no-ff, squash
+                           ou rebase (rebase da origem seguido de fast-forward)
+  --merge-message <tmpl>   template da mensagem do merge, ex.: "Merge {{.Source}} ({{.Version}})"
+  --on-conflict <ação>     em conflitos de merge: ask (padrão), abort, mergetool ou stop
+  --sign                   assinar a tag da versão com GPG ou SSH e verificá-la antes do push
+  --override-policy        executar mesmo violando a política de promoção; cada violação é
+                           registrada no log e no resumo
+  --pull-request           enviar a origem e abrir um pull request (GitHub) ou merge request
+                           (GitLab) para o destino, em vez do merge e push locais
+  --auto-merge             habilitar o auto-merge do pull request
+  --wait=<bool>            aguardar o merge do pull request e seguir com a tag e a release
+                           no commit do merge (padrão: true)
+  --wait-timeout <tempo>   tempo máximo de espera pelo merge (padrão: 30m)
+  --changelog <arquivo>    atualizar o arquivo de changelog no commit da release
+  --non-interactive        nunca exibir perguntas, mesmo em um terminal
+  --dry-run                mostrar os comandos git e requisições de API sem executá-los
+  --config <arquivo>       arquivo de configuração do repositório (padrão: .versionmanager.yml)
+
+Flags do comando verify:
+  --allowed-signers <arq>  arquivo allowed signers do ssh-keygen com as chaves SSH permitidas
+  --allowed-key <chave>    fingerprint ou ID de chave GPG permitida (pode ser repetido)
+  --config <arquivo>       arquivo de configuração do repositório
+
+Flags de log, aceitas por todos os comandos:
+  --quiet                  exibir apenas avisos e erros
+  --verbose                exibir mensagens de depuração, incluindo cada comando executado e sua duração
+  --log-format <formato>   text (padrão) ou json (um objeto JSON por linha)
+  Erros são sempre escritos na saída de erro. NO_COLOR desativa as cores.
+
+Precedência dos valores: flags > variáveis de ambiente (VERSION_MANAGER_*) >
+.versionmanager.yml do repositório > $XDG_CONFIG_HOME/version-manager/config.yml.
+`)
+}
+
+// stringList is a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
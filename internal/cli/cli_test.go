@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+func TestParsePromoteFlags(t *testing.T) {
+	cfg := config.NewConfig()
+
+	cmd, err := Parse([]string{
+		"promote", "--remote", "origin", "--from", "develop", "--to", "main",
+		"--push", "--bump", "minor", "--release", "github", "--sign", "--override-policy", "--delete-remote",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cmd.Name != CommandPromote {
+		t.Errorf("Comando: esperava '%s', obteve '%s'", CommandPromote, cmd.Name)
+	}
+
+	if cfg.Remote != "origin" || cfg.SourceBranch != "develop" || cfg.DestinationBranch != "main" {
+		t.Errorf("Branches/remoto não preenchidos corretamente: %+v", cfg)
+	}
+
+	if !cfg.Push || cfg.Tag != "minor" || !cfg.CreateRelease || cfg.RepoType != "github" || !cfg.Signing.Tags || !cfg.Policy.Override ||
+		!cfg.DeleteRemoteBranch {
+		t.Errorf("Opções não preenchidas corretamente: %+v", cfg)
+	}
+
+	for _, key := range []string{"remote", "from", "to", "push", "bump", "release"} {
+		if !cmd.Provided[key] {
+			t.Errorf("Esperava que '%s' estivesse marcado como informado", key)
+		}
+	}
+
+	if cmd.Provided["remove-branch"] {
+		t.Error("'remove-branch' não foi informado e não deveria estar marcado")
+	}
+}
+
+func TestParseWithoutSubcommand(t *testing.T) {
+	cfg := config.NewConfig()
+
+	cmd, err := Parse([]string{"--from", "feature", "--non-interactive"}, cfg)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cmd.Name != CommandPromote {
+		t.Errorf("Comando: esperava '%s', obteve '%s'", CommandPromote, cmd.Name)
+	}
+
+	if cmd.Interactive {
+		t.Error("--non-interactive deveria desativar as perguntas")
+	}
+
+	if cfg.SourceBranch != "feature" {
+		t.Errorf("SourceBranch: esperava 'feature', obteve '%s'", cfg.SourceBranch)
+	}
+}
+
+func TestParseReleaseAuto(t *testing.T) {
+	cfg := config.NewConfig()
+
+	if _, err := Parse([]string{"--release", "auto"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if !cfg.CreateRelease || cfg.RepoType != "" {
+		t.Errorf("--release auto deveria criar a release e deixar o provedor para a detecção, obteve %v '%s'", cfg.CreateRelease, cfg.RepoType)
+	}
+}
+
+func TestParseRepeatedAssets(t *testing.T) {
+	cfg := config.NewConfig()
+
+	if _, err := Parse([]string{"--asset", "dist/*.zip", "--asset", "dist/*.tar.gz"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if strings.Join(cfg.ReleaseAssets, ",") != "dist/*.zip,dist/*.tar.gz" {
+		t.Errorf("Esperava os dois padrões de --asset, obteve %v", cfg.ReleaseAssets)
+	}
+}
+
+func TestParseReleasePublish(t *testing.T) {
+	cfg := config.NewConfig()
+
+	cmd, err := Parse([]string{"release", "publish", "--release", "gitea", "--make-latest", "legacy", "v1.2.0"}, cfg)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cmd.Name != CommandRelease || strings.Join(cmd.Args, " ") != "publish v1.2.0" {
+		t.Errorf("Esperava o comando release publish v1.2.0, obteve %s %v", cmd.Name, cmd.Args)
+	}
+	if cfg.RepoType != "gitea" || cfg.ReleaseMakeLatest != "legacy" || cfg.Remote != "origin" {
+		t.Errorf("Configuração inesperada: %q %q %q", cfg.RepoType, cfg.ReleaseMakeLatest, cfg.Remote)
+	}
+
+	for _, args := range [][]string{{"release"}, {"release", "publish"}, {"release", "delete", "v1.0.0"}} {
+		if _, err := Parse(args, config.NewConfig()); err == nil {
+			t.Errorf("Esperava erro para %v", args)
+		}
+	}
+}
+
+func TestParseMergeFlagsOverrideRules(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Merge.Rules = []config.MergeRule{{Branch: "main", Strategy: config.MergeSquash, Message: "Squash {{.Source}}"}}
+
+	if _, err := Parse([]string{"promote", "--merge-strategy", "rebase", "--merge-message", "Merge {{.Source}}"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if strategy := cfg.MergeStrategyFor("main"); strategy != config.MergeRebase {
+		t.Errorf("Esperava a estratégia da flag, obteve %s", strategy)
+	}
+	if message := cfg.MergeMessageFor("main"); message != "Merge {{.Source}}" {
+		t.Errorf("Esperava a mensagem da flag, obteve %s", message)
+	}
+}
+
+func TestParsePullRequestFlags(t *testing.T) {
+	cfg := config.NewConfig()
+
+	if _, err := Parse([]string{"promote", "--pull-request", "--auto-merge", "--wait-timeout", "45m"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !cfg.PullRequest.Enabled || !cfg.PullRequest.AutoMerge || !cfg.PullRequest.Wait || cfg.PullRequest.Timeout != 45*time.Minute {
+		t.Errorf("Opções de pull request inesperadas: %+v", cfg.PullRequest)
+	}
+
+	cfg = config.NewConfig()
+	if _, err := Parse([]string{"promote", "--pull-request", "--wait=false"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.PullRequest.Wait {
+		t.Error("--wait=false deveria desativar a espera pelo merge")
+	}
+}
+
+func TestParseLogFlags(t *testing.T) {
+	cmd, err := Parse([]string{"verify", "--verbose", "--log-format", "json"}, config.NewConfig())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cmd.LogLevel != utils.LevelDebug || cmd.LogFormat != utils.LogFormatJSON {
+		t.Errorf("Esperava log debug em JSON, obteve %s em %s", cmd.LogLevel, cmd.LogFormat)
+	}
+
+	cmd, err = Parse([]string{"promote", "--quiet"}, config.NewConfig())
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cmd.LogLevel != utils.LevelWarn || cmd.LogFormat != utils.LogFormatText {
+		t.Errorf("Esperava log de avisos em texto, obteve %s em %s", cmd.LogLevel, cmd.LogFormat)
+	}
+}
+
+func TestParseVerify(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"verify"}, []string{"", ""}},
+		{[]string{"verify", "v1.0.0..v1.4.0"}, []string{"v1.0.0", "v1.4.0"}},
+		{[]string{"verify", "v1.0.0.."}, []string{"v1.0.0", ""}},
+		{[]string{"verify", "v1.2.0"}, []string{"v1.2.0"}},
+	}
+
+	for _, tc := range testCases {
+		cmd, err := Parse(tc.args, config.NewConfig())
+		if err != nil {
+			t.Fatalf("Erro inesperado para %v: %v", tc.args, err)
+		}
+		if cmd.Name != CommandVerify || strings.Join(cmd.Args, "|") != strings.Join(tc.expected, "|") {
+			t.Errorf("%v: esperava o intervalo %q, obteve %s %q", tc.args, tc.expected, cmd.Name, cmd.Args)
+		}
+	}
+
+	cfg := config.NewConfig()
+	if _, err := Parse([]string{"verify", "--allowed-signers", "signers", "--allowed-key", "AAAA", "--allowed-key", "BBBB"}, cfg); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.Signing.AllowedSigners != "signers" || strings.Join(cfg.Signing.AllowedKeys, ",") != "AAAA,BBBB" {
+		t.Errorf("Chaves permitidas inesperadas: %+v", cfg.Signing)
+	}
+
+	if _, err := Parse([]string{"verify", "v1.0.0", "v2.0.0"}, config.NewConfig()); err == nil {
+		t.Error("Esperava erro para dois argumentos")
+	}
+}
+
+func TestParseInvalidValues(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{"Unknown Command", []string{"deploy"}},
+		{"Unknown Flag", []string{"promote", "--force"}},
+		{"Invalid Bump", []string{"promote", "--bump", "huge"}},
+		{"Invalid Release", []string{"promote", "--release", "svn"}},
+		{"Same Branches", []string{"promote", "--from", "main", "--to", "main"}},
+		{"Invalid Existing Policy", []string{"promote", "--release-existing", "replace"}},
+		{"Invalid Make Latest", []string{"promote", "--make-latest", "maybe"}},
+		{"Invalid Merge Strategy", []string{"promote", "--merge-strategy", "octopus"}},
+		{"Invalid Merge Message", []string{"promote", "--merge-message", "{{.Source"}},
+		{"Invalid Conflict Action", []string{"promote", "--on-conflict", "theirs"}},
+		{"Invalid Wait Timeout", []string{"promote", "--wait-timeout", "0s"}},
+		{"Quiet And Verbose", []string{"promote", "--quiet", "--verbose"}},
+		{"Invalid Log Format", []string{"promote", "--log-format", "xml"}},
+		{"Invalid Released At", []string{"promote", "--released-at", "amanhã"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(tc.args, config.NewConfig()); err == nil {
+				t.Errorf("Esperava erro para os argumentos %v", tc.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/release"
+)
+
+// removeSourceBranch deletes the source branch once it is fully merged into
+// the destination and, when configured, deletes it on the remote too. A branch
+// with unmerged work is kept. The tips of deleted branches are logged so that
+// they can be restored.
+func (m *Manager) removeSourceBranch() error {
+	if !m.config.RemoveBranch {
+		return nil
+	}
+
+	source, destination := m.config.SourceBranch, m.config.DestinationBranch
+
+	merged, err := m.isMerged(source, destination)
+	if err != nil {
+		return err
+	}
+	if !merged {
+		m.logger.Warning("%s has commits that are not in %s, so it was not removed", source, destination)
+		m.summarize("kept branch %s: not fully merged into %s", source, destination)
+		return nil
+	}
+
+	var tip string
+	spinner := utils.NewProgressSpinner(fmt.Sprintf("Removing source branch: %s", source))
+
+	err = spinner.WithDelay(func() error {
+		var err error
+		if tip, err = m.gitCmd.RevParse(source); err != nil {
+			return err
+		}
+
+		if err := m.gitCmd.RemoveBranch(source); err != nil {
+			return err
+		}
+
+		m.journal.record(fmt.Sprintf("remove branch %s", source), func() error {
+			return m.gitCmd.CreateBranch(source, tip)
+		})
+		return nil
+	}, m.delayTime)
+
+	if err != nil {
+		return err
+	}
+
+	m.logger.Success("Successfully removed source branch: %s (was %s)", source, shortHash(tip))
+	m.logger.Info("Restore it with: git branch %s %s", source, tip)
+	m.summarize("removed branch %s at %s", source, tip)
+
+	if m.config.DeleteRemoteBranch {
+		return m.deleteRemoteSourceBranch()
+	}
+	return nil
+}
+
+// deleteRemoteSourceBranch deletes the source branch on the remote with a
+// push, or through the provider API when the remote protects it.
+func (m *Manager) deleteRemoteSourceBranch() error {
+	remote, source, destination := m.config.Remote, m.config.SourceBranch, m.config.DestinationBranch
+
+	if remote == "" {
+		m.logger.Warning("No remote configured, %s was only removed locally", source)
+		return nil
+	}
+
+	remoteBranch := remote + "/" + source
+	tip, err := m.gitCmd.RevParse(remoteBranch)
+	if err != nil {
+		m.logger.Info("%s has no branch %s, nothing to delete there", remote, source)
+		return nil
+	}
+
+	// Someone may have pushed to the remote branch after it was merged.
+	merged, err := m.isMerged(remoteBranch, destination)
+	if err != nil {
+		return err
+	}
+	if !merged {
+		m.logger.Warning("%s has commits that are not in %s, so it was not deleted", remoteBranch, destination)
+		m.summarize("kept branch %s: not fully merged into %s", remoteBranch, destination)
+		return nil
+	}
+
+	spinner := utils.NewProgressSpinner(fmt.Sprintf("Deleting %s from %s", source, remote))
+	err = spinner.WithDelay(func() error {
+		err := m.gitCmd.DeleteRemoteBranch(remote, source)
+
+		var protected *utils.ProtectedBranchError
+		if errors.As(err, &protected) {
+			err = m.deleteBranchThroughAPI(source)
+		}
+		if err != nil {
+			return err
+		}
+
+		m.journal.recordRemote(fmt.Sprintf("deleted branch %s on %s", source, remote))
+		return nil
+	}, m.delayTime)
+
+	if err != nil {
+		return err
+	}
+
+	m.logger.Success("Deleted %s from %s (was %s)", source, remote, shortHash(tip))
+	m.logger.Info("Restore it with: git push %s %s:refs/heads/%s", remote, tip, source)
+	m.summarize("deleted branch %s on %s at %s", source, remote, tip)
+	return nil
+}
+
+// deleteBranchThroughAPI deletes a protected branch through the provider API.
+func (m *Manager) deleteBranchThroughAPI(branch string) error {
+	m.logger.Info("%s is protected on %s, deleting it through the provider API", branch, m.config.Remote)
+
+	deleter := m.branchDeleter
+	if deleter == nil {
+		var err error
+		if deleter, err = release.NewReleaseManagerWithTransport(m.config, m.releaseTransport).BranchDeleter(); err != nil {
+			return err
+		}
+	}
+
+	if err := deleter.DeleteBranch(context.Background(), branch); err != nil {
+		return fmt.Errorf("could not delete protected branch %s through the provider API: %v", branch, err)
+	}
+	return nil
+}
+
+// isMerged reports whether everything in ref is already in destination:
+// either ref is an ancestor of destination, as after a merge, fast-forward or
+// rebase, or merging it would not change destination, as after a squash.
+func (m *Manager) isMerged(ref string, destination string) (bool, error) {
+	ahead, _, err := m.gitCmd.AheadBehind(ref, destination)
+	if err != nil {
+		return false, err
+	}
+	if ahead == 0 {
+		return true, nil
+	}
+
+	// merge-tree fails on conflicts, which mean that ref is not merged.
+	merged, err := m.gitCmd.MergeTree(destination, ref)
+	if err != nil {
+		return false, nil
+	}
+
+	tree, err := m.gitCmd.Tree(destination)
+	if err != nil {
+		return false, err
+	}
+	return merged == tree, nil
+}
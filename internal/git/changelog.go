@@ -0,0 +1,87 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/changelog"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/remote"
+)
+
+// GenerateReleaseNotes renders the Markdown changelog for the commits that
+// newTag adds on top of lastTag, linking commits and issues to the forge of
+// cfg.Remote when it can be detected.
+func GenerateReleaseNotes(gitCmd *utils.GitCommands, cfg *config.Config, lastTag string, newTag string) (string, error) {
+	return generateReleaseNotes(gitCmd, cfg, lastTag, newTag, "")
+}
+
+// generateReleaseNotes is GenerateReleaseNotes restricted to the commits
+// touching dir, the path of a monorepo component.
+func generateReleaseNotes(gitCmd *utils.GitCommands, cfg *config.Config, lastTag string, newTag string, dir string) (string, error) {
+	commits, err := gitCmd.CommitsSinceIn(lastTag, dir, cfg.DestinationBranch, cfg.SourceBranch)
+	if err != nil {
+		return "", err
+	}
+
+	var links *changelog.Links
+	if cfg.Remote != "" {
+		if rawURL, err := gitCmd.RemoteURL(cfg.Remote); err == nil {
+			if repo, err := remote.Parse(rawURL); err == nil {
+				provider := cfg.RepoType
+				if provider == "" {
+					provider = cfg.ProviderFor(repo.Host)
+				}
+				links = changelog.NewLinks(repo, provider)
+			}
+		}
+	}
+
+	return changelog.Generate(commits, changelog.Options{
+		Version:     newTag,
+		PreviousTag: lastTag,
+		Date:        time.Now(),
+		GroupBy:     cfg.ChangelogGroupBy,
+		Links:       links,
+	}), nil
+}
+
+// writeChangelog prepends the notes for newTag to the configured changelog
+// file and returns its path, or "" when no changelog is configured. For a
+// monorepo component, dir is its path and holds its own changelog file.
+func (m *Manager) writeChangelog(lastTag string, newTag string, dir string) (string, error) {
+	if m.config.ChangelogFile == "" {
+		return "", nil
+	}
+
+	notes, err := generateReleaseNotes(m.gitCmd, m.config, lastTag, newTag, dir)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, m.config.ChangelogFile)
+
+	if m.plan != nil {
+		m.plan.Add(fmt.Sprintf("prepend %s section to %s", newTag, path))
+		return path, nil
+	}
+
+	_, statErr := os.Stat(path)
+	existed := !errors.Is(statErr, os.ErrNotExist)
+
+	if err := changelog.Prepend(path, notes); err != nil {
+		return "", err
+	}
+
+	if !existed {
+		m.journal.record(fmt.Sprintf("create %s", path), func() error {
+			return os.Remove(path)
+		})
+	}
+
+	return path, nil
+}
@@ -0,0 +1,133 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+// componentTag is the tag created for a monorepo component in this run.
+type componentTag struct {
+	component config.Component
+	lastTag   string
+	tag       string
+}
+
+// releaseComponents tags every component with changes under its path since
+// its latest tag, pushes the tags and, when enabled, creates one release each.
+func (m *Manager) releaseComponents() error {
+	tags, err := m.createComponentTags()
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		m.logger.Info("No component changed since its latest tag, nothing to tag")
+		return nil
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.tag)
+	}
+
+	if err := m.updateTagOnRemote(names...); err != nil {
+		return err
+	}
+
+	if !m.config.CreateRelease {
+		return nil
+	}
+
+	for _, t := range tags {
+		if err := m.createComponentRelease(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) createComponentTags() ([]componentTag, error) {
+	var tags []componentTag
+
+	for _, component := range m.config.Components {
+		spinner := utils.NewProgressSpinner(fmt.Sprintf("Checking component %s", component.Name))
+
+		var created *componentTag
+		var drivers []version.Commit
+
+		err := spinner.WithDelay(func() error {
+			lastTag, err := m.gitCmd.GetLatestTagFrom(m.config.DestinationBranch, component.TagPrefix)
+			if err != nil {
+				lastTag = ""
+			}
+
+			if lastTag != "" {
+				changed, err := m.gitCmd.ChangedFiles(lastTag, "HEAD", component.Path)
+				if err != nil {
+					return err
+				}
+				if len(changed) == 0 {
+					return nil
+				}
+			}
+
+			newTag, commits, err := m.tagVersion(lastTag, component.TagPrefix, component.Path, component.VersionFiles)
+			if err != nil {
+				return fmt.Errorf("component %s: %v", component.Name, err)
+			}
+
+			created = &componentTag{component: component, lastTag: lastTag, tag: newTag}
+			drivers = commits
+			return nil
+		}, m.delayTime)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if created == nil {
+			m.logger.Info("Component %s has no changes under %s, skipping", component.Name, component.Path)
+			continue
+		}
+
+		if m.config.Tag == version.BumpAuto {
+			m.logAutoBump(drivers)
+		}
+
+		m.logger.Success("Successfully created version tag for %s: %s", component.Name, created.tag)
+		tags = append(tags, *created)
+	}
+
+	return tags, nil
+}
+
+// createComponentRelease creates the release of a component, titled after its
+// tag and described by the notes of the commits under its path.
+func (m *Manager) createComponentRelease(t componentTag) error {
+	spinner := utils.NewProgressSpinner(fmt.Sprintf("Creating release for tag %s on %s", t.tag, m.config.RepoType))
+
+	err := spinner.WithDelay(func() error {
+		notes, err := generateReleaseNotes(m.gitCmd, m.config, t.lastTag, t.tag, t.component.Path)
+		if err != nil {
+			return err
+		}
+
+		cfg := *m.config
+		cfg.ReleaseTitle = fmt.Sprintf("%s %s", t.component.Name, strings.TrimPrefix(t.tag, t.component.TagPrefix))
+		cfg.ReleaseNotes = notes
+
+		return m.publishRelease(&cfg, t.tag)
+	}, m.delayTime)
+
+	if err != nil {
+		return fmt.Errorf("falha ao criar release de %s: %v", t.component.Name, err)
+	}
+
+	m.logger.Success("Release de %s criada com sucesso!", t.component.Name)
+	return nil
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+// ErrMergeInProgress is returned when the run stops on a merge conflict that
+// is left in progress for manual resolution. Nothing is rolled back then.
+var ErrMergeInProgress = errors.New("merge left in progress for manual resolution")
+
+// SetConflictResolver sets the function asked what to do, one of
+// config.ConflictAbort, ConflictMergetool or ConflictStop, when a merge stops
+// on conflicts and merge.on_conflict is "ask". Without it the merge is aborted.
+func (m *Manager) SetConflictResolver(resolve func(conflict *utils.MergeConflictError) (string, error)) {
+	m.conflictResolver = resolve
+}
+
+// resolveConflict handles a merge of source into destination that stopped on
+// conflict: it aborts it, has it resolved with the mergetool and commits it,
+// or leaves it in progress. A rebase of source onto destination is continued
+// the same way, one conflicting commit at a time. The outcome goes to the run
+// summary.
+func (m *Manager) resolveConflict(conflict *utils.MergeConflictError, source string, destination string, strategy string, message string) error {
+	for {
+		m.logger.Error("Merge of %s into %s stopped on conflicts in:", source, destination)
+		for _, path := range conflict.Paths {
+			m.logger.Error("  - %s", path)
+		}
+
+		action, err := m.conflictAction(conflict)
+		if err != nil {
+			return err
+		}
+
+		switch action {
+		case config.ConflictMergetool:
+			if err := m.gitCmd.MergeTool(); err != nil {
+				return err
+			}
+
+			paths, err := m.gitCmd.ConflictedPaths()
+			if err != nil {
+				return err
+			}
+			if len(paths) > 0 {
+				conflict.Paths = paths
+				continue
+			}
+
+			if err := m.continueMerge(source, destination, strategy, message); err != nil {
+				// A later commit of the rebase stopped on conflicts as well.
+				var next *utils.MergeConflictError
+				if errors.As(err, &next) {
+					conflict = next
+					continue
+				}
+				return err
+			}
+			m.summarize("merge of %s into %s: conflicts resolved with mergetool", source, destination)
+			return nil
+
+		case config.ConflictStop:
+			m.summarize("merge of %s into %s: stopped on conflicts, merge left in progress", source, destination)
+			if conflict.Rebase {
+				m.logger.Warning("Resolve the conflicts and run \"git rebase --continue\", or run \"git rebase --abort\"; the run was not rolled back")
+			} else {
+				m.logger.Warning("Resolve the conflicts and commit, or run \"git merge --abort\"; the run was not rolled back")
+			}
+			return fmt.Errorf("%w: %v", ErrMergeInProgress, conflict)
+
+		default:
+			if conflict.Rebase {
+				if err := m.gitCmd.RebaseAbort(); err != nil {
+					return err
+				}
+				if err := m.gitCmd.Checkout(destination); err != nil {
+					return err
+				}
+				m.summarize("merge of %s into %s: aborted on conflicts", source, destination)
+				return conflict
+			}
+
+			// A squash merge has no MERGE_HEAD to abort; the rollback resets it.
+			if err := m.gitCmd.MergeAbort(); err != nil && strategy != config.MergeSquash {
+				return err
+			}
+			m.summarize("merge of %s into %s: aborted on conflicts", source, destination)
+			return conflict
+		}
+	}
+}
+
+// conflictAction returns what to do with conflict, asking when configured to.
+func (m *Manager) conflictAction(conflict *utils.MergeConflictError) (string, error) {
+	action := m.config.Merge.OnConflict
+	if action != "" && action != config.ConflictAsk {
+		return action, nil
+	}
+	if m.conflictResolver == nil {
+		return config.ConflictAbort, nil
+	}
+	return m.conflictResolver(conflict)
+}
+
+// continueMerge commits a merge whose conflicts were resolved, or continues
+// the rebase.
+func (m *Manager) continueMerge(source string, destination string, strategy string, message string) error {
+	if strategy == config.MergeRebase {
+		return m.continueRebase(source, destination)
+	}
+	if strategy == config.MergeSquash {
+		return m.gitCmd.Commit(squashMessage(source, destination, message))
+	}
+	return m.gitCmd.CommitNoEdit()
+}
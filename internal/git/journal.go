@@ -0,0 +1,66 @@
+package git
+
+import "github.com/be-tech/version-manager/internal/utils"
+
+// journalEntry is one effect of the version flow. Local effects carry the
+// compensating action that undoes them; remote effects cannot be undone and
+// are only reported.
+type journalEntry struct {
+	description string
+	undo        func() error
+	remote      bool
+}
+
+// journal records the effects of ExecuteVersionFlow so a failure halfway
+// through can be compensated in reverse order.
+type journal struct {
+	entries []journalEntry
+}
+
+func newJournal() *journal {
+	return &journal{}
+}
+
+// record adds a local effect and the action that undoes it.
+func (j *journal) record(description string, undo func() error) {
+	j.entries = append(j.entries, journalEntry{description: description, undo: undo})
+}
+
+// recordRemote adds an effect on the remote that rollback cannot undo.
+func (j *journal) recordRemote(description string) {
+	j.entries = append(j.entries, journalEntry{description: description, remote: true})
+}
+
+// rollback runs the compensating actions in reverse order, keeps going when
+// one fails, and reports the remote effects left in place. It returns the
+// number of local actions that could not be undone.
+func (j *journal) rollback(logger utils.Logger) int {
+	failures := 0
+	var remote []string
+
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		entry := j.entries[i]
+
+		if entry.remote {
+			remote = append(remote, entry.description)
+			continue
+		}
+
+		if err := entry.undo(); err != nil {
+			logger.Error("Could not undo %q: %v", entry.description, err)
+			failures++
+			continue
+		}
+
+		logger.Success("Undid: %s", entry.description)
+	}
+
+	if len(remote) > 0 {
+		logger.Warning("The following remote changes were NOT undone and must be reverted by hand:")
+		for i := len(remote) - 1; i >= 0; i-- {
+			logger.Warning("  - %s", remote[i])
+		}
+	}
+
+	return failures
+}
@@ -1,11 +1,15 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"os/exec"
 	"time"
 
 	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/bump"
 	"github.com/be-tech/version-manager/pkg/config"
 	"github.com/be-tech/version-manager/pkg/release"
 	"github.com/be-tech/version-manager/pkg/version"
@@ -23,11 +27,27 @@ func (r *DefaultCommandRunner) Output(name string, args ...string) ([]byte, erro
 	return cmd.Output()
 }
 
+func (r *DefaultCommandRunner) RunInteractive(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
 type Manager struct {
-	config    *config.Config
-	gitCmd    *utils.GitCommands
-	logger    *utils.Logger
-	delayTime time.Duration
+	config           *config.Config
+	gitCmd           *utils.GitCommands
+	logger           utils.Logger
+	delayTime        time.Duration
+	releaseTransport http.RoundTripper
+	plan             *utils.DryRunPlan
+	journal          *journal
+	currentBranch    string
+	rebasedSource    bool
+	summary          []string
+	preflightConfirm func(report *PreflightReport) (bool, error)
+	conflictResolver func(conflict *utils.MergeConflictError) (string, error)
+	pullRequester    release.PullRequester
+	branchDeleter    release.BranchDeleter
 }
 
 func NewManager(config *config.Config) *Manager {
@@ -36,6 +56,7 @@ func NewManager(config *config.Config) *Manager {
 		gitCmd:    utils.NewGitCommands(&DefaultCommandRunner{}),
 		logger:    utils.NewLogger(),
 		delayTime: 2 * time.Second,
+		journal:   newJournal(),
 	}
 }
 
@@ -45,34 +66,118 @@ func NewManagerWithRunner(config *config.Config, runner utils.CommandRunner) *Ma
 		gitCmd:    utils.NewGitCommands(runner),
 		logger:    utils.NewLogger(),
 		delayTime: 2 * time.Second,
+		journal:   newJournal(),
 	}
 }
 
+// NewDryRunManager creates a Manager that reads the real repository state but
+// only records the git commands and release API requests it would send.
+func NewDryRunManager(config *config.Config) *Manager {
+	plan := utils.NewDryRunPlan()
+
+	manager := NewManagerWithRunner(config, utils.NewRecordingCommandRunner(&DefaultCommandRunner{}, plan))
+	manager.delayTime = 0
+	manager.releaseTransport = release.NewRecordingTransport(plan.Add)
+	manager.plan = plan
+
+	return manager
+}
+
 func (m *Manager) ExecuteVersionFlow() error {
-	m.logger.Title("Starting deploy!")
+	if m.plan != nil {
+		m.logger.Title("Dry run: nothing will be changed")
+		defer m.printPlan()
+	} else {
+		m.logger.Title("Starting deploy!")
+	}
 
-	if err := m.checkoutDestinationBranch(); err != nil {
+	violations, err := m.policyViolations()
+	if err != nil {
+		return err
+	}
+	if err := m.enforcePolicy(violations...); err != nil {
 		return err
 	}
 
-	if err := m.mergeBranches(false); err != nil {
+	if err := m.preflight(); err != nil {
 		return err
 	}
 
-	if err := m.pushToRemote(false); err != nil {
+	originalBranch, err := m.gitCmd.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	m.journal = newJournal()
+	m.currentBranch = originalBranch
+	defer m.printSummary()
+
+	if err := m.runVersionFlow(); err != nil {
+		if errors.Is(err, ErrMergeInProgress) {
+			return err
+		}
+		m.rollback()
 		return err
 	}
 
+	return nil
+}
+
+// summarize records an outcome of the run worth reporting at its end.
+func (m *Manager) summarize(format string, a ...interface{}) {
+	m.summary = append(m.summary, fmt.Sprintf(format, a...))
+}
+
+// Summary returns the outcomes recorded during the last run.
+func (m *Manager) Summary() []string {
+	return m.summary
+}
+
+func (m *Manager) printSummary() {
+	if len(m.summary) == 0 {
+		return
+	}
+
+	m.logger.Title("Run summary")
+	for _, line := range m.summary {
+		m.logger.Info("  - %s", line)
+	}
+}
+
+func (m *Manager) runVersionFlow() error {
+	if m.config.PullRequest.Enabled {
+		merged, err := m.mergeThroughPullRequest()
+		if err != nil || !merged {
+			return err
+		}
+	} else {
+		if err := m.checkoutDestinationBranch(); err != nil {
+			return err
+		}
+
+		if err := m.mergeBranches(false); err != nil {
+			return err
+		}
+
+		if err := m.pushToRemote(false); err != nil {
+			return err
+		}
+	}
+
 	var newTagVersion string
 	var err error
 
-	if m.config.Tag != "" {
+	if m.config.Tag != "" && len(m.config.Components) > 0 {
+		if err := m.releaseComponents(); err != nil {
+			return err
+		}
+	} else if m.config.Tag != "" {
 		newTagVersion, err = m.createVersionTag()
 		if err != nil {
 			return err
 		}
 
-		if err := m.updateTagOnRemote(); err != nil {
+		if err := m.updateTagOnRemote(newTagVersion); err != nil {
 			return err
 		}
 
@@ -104,11 +209,39 @@ func (m *Manager) ExecuteVersionFlow() error {
 	return nil
 }
 
+// rollback undoes the local effects recorded so far, most recent first.
+func (m *Manager) rollback() {
+	m.logger.Warning("Version flow failed, rolling back local changes")
+
+	if failures := m.journal.rollback(m.logger); failures > 0 {
+		m.logger.Error("%d local change(s) could not be undone, check the repository state", failures)
+		return
+	}
+
+	m.logger.Success("Local repository restored")
+}
+
+// checkout switches to branch and records how to return to the previous one.
+func (m *Manager) checkout(branch string) error {
+	if err := m.gitCmd.Checkout(branch); err != nil {
+		return err
+	}
+
+	if previous := m.currentBranch; previous != "" {
+		m.journal.record(fmt.Sprintf("checkout %s", branch), func() error {
+			return m.gitCmd.Checkout(previous)
+		})
+	}
+	m.currentBranch = branch
+
+	return nil
+}
+
 func (m *Manager) checkoutDestinationBranch() error {
 	spinner := utils.NewProgressSpinner(fmt.Sprintf("Checking out to destination branch: %s", m.config.DestinationBranch))
 
 	err := spinner.WithDelay(func() error {
-		return m.gitCmd.Checkout(m.config.DestinationBranch)
+		return m.checkout(m.config.DestinationBranch)
 	}, m.delayTime)
 
 	if err != nil {
@@ -127,7 +260,7 @@ func (m *Manager) checkoutSourceBranch() error {
 	spinner := utils.NewProgressSpinner(fmt.Sprintf("Checking out to source branch: %s", m.config.SourceBranch))
 
 	err := spinner.WithDelay(func() error {
-		return m.gitCmd.Checkout(m.config.SourceBranch)
+		return m.checkout(m.config.SourceBranch)
 	}, m.delayTime)
 
 	if err != nil {
@@ -141,22 +274,54 @@ func (m *Manager) checkoutSourceBranch() error {
 func (m *Manager) mergeBranches(returning bool) error {
 	var source, destination, mergeMessage string
 
+	// The merge back into the source only syncs it, so it always is a plain
+	// merge; the strategy and message apply to the merge into the destination.
+	strategy := config.MergeDefault
+	var commitMessage string
+
 	if !returning {
 		source = m.config.SourceBranch
 		destination = m.config.DestinationBranch
 		mergeMessage = fmt.Sprintf("Merging %s into %s", source, destination)
+
+		strategy = m.config.MergeStrategyFor(destination)
+		message, err := m.mergeMessage(source, destination)
+		if err != nil {
+			return err
+		}
+		commitMessage = message
 	} else {
 		source = m.config.DestinationBranch
 		destination = m.config.SourceBranch
 		mergeMessage = fmt.Sprintf("Merging %s into %s", source, destination)
 	}
 
+	if strategy != config.MergeDefault {
+		mergeMessage = fmt.Sprintf("%s (%s)", mergeMessage, strategy)
+	}
+
 	spinner := utils.NewProgressSpinner(mergeMessage)
 
 	err := spinner.WithDelay(func() error {
-		return m.gitCmd.Merge(source)
+		previousHead, err := m.gitCmd.RevParse("HEAD")
+		if err != nil {
+			return err
+		}
+
+		// Recorded before merging so a half-done merge is reset as well.
+		m.journal.record(fmt.Sprintf("merge %s into %s", source, destination), func() error {
+			return m.gitCmd.ResetHard(previousHead)
+		})
+
+		return m.merge(source, destination, strategy, commitMessage)
 	}, m.delayTime)
 
+	// Resolved outside the spinner, as it may ask the user or run a mergetool.
+	var conflict *utils.MergeConflictError
+	if errors.As(err, &conflict) {
+		err = m.resolveConflict(conflict, source, destination, strategy, commitMessage)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -184,7 +349,21 @@ func (m *Manager) pushToRemote(returning bool) error {
 	spinner := utils.NewProgressSpinner(fmt.Sprintf("Pushing %s to %s", branch, m.config.Remote))
 
 	err := spinner.WithDelay(func() error {
-		return m.gitCmd.Push(m.config.Remote, branch)
+		// A rebased source no longer contains its remote counterpart.
+		if returning && m.rebasedSource {
+			if err := m.gitCmd.PushForceWithLease(m.config.Remote, branch); err != nil {
+				return err
+			}
+			m.journal.recordRemote(fmt.Sprintf("force-pushed rebased %s to %s", branch, m.config.Remote))
+			return nil
+		}
+
+		if err := m.gitCmd.Push(m.config.Remote, branch); err != nil {
+			return err
+		}
+
+		m.journal.recordRemote(fmt.Sprintf("pushed %s to %s", branch, m.config.Remote))
+		return nil
 	}, m.delayTime)
 
 	if err != nil {
@@ -203,80 +382,134 @@ func (m *Manager) createVersionTag() (string, error) {
 	spinner := utils.NewProgressSpinner(fmt.Sprintf("Creating version tag: %s", m.config.Tag))
 
 	var newTag string
+	var drivers []version.Commit
 
 	err := spinner.WithDelay(func() error {
-		lastTag, err := m.gitCmd.GetLatestTag()
+		lastTag, err := m.gitCmd.GetLatestTagFrom(m.config.DestinationBranch, m.config.TagPrefix)
 		if err != nil {
 			lastTag = ""
 		}
 
-		versionHandler := version.NewHandler()
-		generatedTag, err := versionHandler.GenerateNewTag(lastTag, m.config.Tag)
-		if err != nil {
-			return fmt.Errorf("failed to generate new tag: %v", err)
-		}
-
-		newTag = generatedTag
-
-		return m.gitCmd.CreateTag(newTag, fmt.Sprintf("Version %s", newTag))
+		newTag, drivers, err = m.tagVersion(lastTag, m.config.TagPrefix, "", m.config.VersionFiles)
+		return err
 	}, m.delayTime)
 
 	if err != nil {
 		return "", err
 	}
 
+	if m.config.Tag == version.BumpAuto {
+		m.logAutoBump(drivers)
+	}
+
 	m.logger.Success("Successfully created version tag: %s", newTag)
 	return newTag, nil
 }
 
-func (m *Manager) updateTagOnRemote() error {
-	if !m.config.Push || m.config.Tag == "" {
-		return nil
+// tagVersion creates the tag that follows lastTag, after the release commit
+// with the changelog and version files. prefix and dir select the version
+// stream: the repository-wide one, or a monorepo component under dir.
+func (m *Manager) tagVersion(lastTag string, prefix string, dir string, versionFiles []bump.Spec) (string, []version.Commit, error) {
+	newTag, drivers, err := m.nextTag(lastTag, prefix, dir)
+	if err != nil {
+		return "", nil, err
 	}
 
-	spinner := utils.NewProgressSpinner("Updating tag in remote repository")
+	if err := m.commitRelease(lastTag, newTag, prefix, dir, versionFiles); err != nil {
+		return "", nil, err
+	}
 
-	err := spinner.WithDelay(func() error {
-		newtag, err := m.gitCmd.GetLatestTag()
+	if err := m.createTag(newTag); err != nil {
+		return "", nil, err
+	}
+
+	m.journal.record(fmt.Sprintf("create tag %s", newTag), func() error {
+		return m.gitCmd.DeleteTag(newTag)
+	})
+
+	return newTag, drivers, nil
+}
+
+// nextTag returns the tag that follows lastTag in the version stream of prefix
+// and dir and, for an automatic bump, the commits that decided it.
+func (m *Manager) nextTag(lastTag string, prefix string, dir string) (string, []version.Commit, error) {
+	versionHandler, err := newVersionHandler(m.gitCmd, m.config, prefix)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var drivers []version.Commit
+
+	bump := m.config.Tag
+	if bump == version.BumpAuto {
+		commits, err := m.gitCmd.CommitsSinceIn(lastTag, dir, m.config.DestinationBranch, m.config.SourceBranch)
 		if err != nil {
-			return fmt.Errorf("failed to get latest tag: %v", err)
+			return "", nil, err
 		}
+		bump, drivers = versionHandler.ResolveBump(lastTag, bump, commits)
 
-		if err := m.gitCmd.PushTag(m.config.Remote, newtag); err != nil {
-			return err
+		// The policy of a component's bump can only be checked here.
+		if violation := m.bumpViolation(bump); dir != "" && violation != nil {
+			if err := m.enforcePolicy(*violation); err != nil {
+				return "", nil, err
+			}
 		}
+	}
 
-		if err := m.gitCmd.Push(m.config.Remote, m.config.DestinationBranch); err != nil {
-			return err
-		}
+	newTag, err := versionHandler.GenerateNewTag(lastTag, bump)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate new tag: %v", err)
+	}
 
-		return nil
-	}, 5*time.Second)
+	return newTag, drivers, nil
+}
 
-	if err != nil {
-		return err
+func (m *Manager) logAutoBump(drivers []version.Commit) {
+	if len(drivers) == 0 {
+		m.logger.Info("No feat, fix, perf or breaking commits found, defaulting to a patch bump")
+		return
 	}
 
-	m.logger.Success("Successfully updated tag in remote repository")
-	return nil
+	m.logger.Info("Version bump inferred from %d commit(s):", len(drivers))
+	for _, commit := range drivers {
+		m.logger.Info("  %s %s", shortHash(commit.Hash), commit.Subject)
+	}
 }
 
-func (m *Manager) removeSourceBranch() error {
-	if !m.config.RemoveBranch {
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func (m *Manager) updateTagOnRemote(newTags ...string) error {
+	if !m.config.Push || len(newTags) == 0 {
 		return nil
 	}
 
-	spinner := utils.NewProgressSpinner(fmt.Sprintf("Removing source branch: %s", m.config.SourceBranch))
+	spinner := utils.NewProgressSpinner("Updating tag in remote repository")
 
 	err := spinner.WithDelay(func() error {
-		return m.gitCmd.RemoveBranch(m.config.SourceBranch)
+		for _, newTag := range newTags {
+			if err := m.gitCmd.PushTag(m.config.Remote, newTag); err != nil {
+				return err
+			}
+			m.journal.recordRemote(fmt.Sprintf("pushed tag %s to %s", newTag, m.config.Remote))
+		}
+
+		if err := m.gitCmd.Push(m.config.Remote, m.config.DestinationBranch); err != nil {
+			return err
+		}
+
+		return nil
 	}, m.delayTime)
 
 	if err != nil {
 		return err
 	}
 
-	m.logger.Success("Successfully removed source branch: %s", m.config.SourceBranch)
+	m.logger.Success("Successfully updated tag in remote repository")
 	return nil
 }
 
@@ -288,8 +521,7 @@ func (m *Manager) createRelease(tagVersion string) error {
 	spinner := utils.NewProgressSpinner(fmt.Sprintf("Creating release for tag %s on %s", tagVersion, m.config.RepoType))
 
 	err := spinner.WithDelay(func() error {
-		releaseManager := release.NewReleaseManager(m.config)
-		return releaseManager.CreateRelease(tagVersion)
+		return m.publishRelease(m.config, tagVersion)
 	}, m.delayTime)
 
 	if err != nil {
@@ -299,3 +531,23 @@ func (m *Manager) createRelease(tagVersion string) error {
 	m.logger.Success("Release criada com sucesso!")
 	return nil
 }
+
+// publishRelease creates the release for tagVersion using cfg's title and notes.
+func (m *Manager) publishRelease(cfg *config.Config, tagVersion string) error {
+	releaseManager := release.NewReleaseManagerWithTransport(cfg, m.releaseTransport)
+	if err := releaseManager.CreateRelease(tagVersion); err != nil {
+		return err
+	}
+
+	m.journal.recordRemote(fmt.Sprintf("published %s release for %s", cfg.RepoType, tagVersion))
+	return nil
+}
+
+func (m *Manager) printPlan() {
+	steps := m.plan.Steps()
+
+	m.logger.Title("Dry run plan (%d steps)", len(steps))
+	for i, step := range steps {
+		m.logger.Info("%3d. %s", i+1, step)
+	}
+}
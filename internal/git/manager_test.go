@@ -1,10 +1,19 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/bump"
 	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/release"
 )
 
 // MockCommandRunner é uma implementação simulada de CommandRunner para testes
@@ -14,6 +23,9 @@ type MockCommandRunner struct {
 		output []byte
 		err    error
 	}
+
+	// Comandos executados, na ordem em que foram chamados
+	executed []string
 }
 
 // NewMockCommandRunner cria uma nova instância de MockCommandRunner
@@ -42,6 +54,8 @@ func (m *MockCommandRunner) Run(name string, args ...string) ([]byte, error) {
 		cmd += " " + arg
 	}
 
+	m.executed = append(m.executed, cmd)
+
 	// Verifica se o comando está no mapa de resultados simulados
 	if result, ok := m.commandResults[cmd]; ok {
 		return result.output, result.err
@@ -139,6 +153,7 @@ func TestMergeBranches(t *testing.T) {
 
 	// Criar o mock runner
 	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
 	mockRunner.AddMockResult("git merge feature", []byte("Updating abc123..def456\nFast-forward"), nil)
 
 	// Criar manager com o mock
@@ -167,7 +182,7 @@ func TestCreateVersionTag_NoTag(t *testing.T) {
 	manager := NewManagerWithRunner(cfg, mockRunner)
 
 	// O método deve retornar nil quando não há tag
-	err := manager.createVersionTag()
+	_, err := manager.createVersionTag()
 	if err != nil {
 		t.Errorf("createVersionTag com tag vazia deve retornar nil, obteve erro: %v", err)
 	}
@@ -193,10 +208,895 @@ func TestCreateVersionTag(t *testing.T) {
 	manager := NewManagerWithRunner(cfg, mockRunner)
 
 	// Executar o método a testar
-	err := manager.createVersionTag()
+	_, err := manager.createVersionTag()
 
 	// Verificar resultado
 	if err != nil {
 		t.Errorf("createVersionTag falhou com erro: %v", err)
 	}
 }
+
+// TestDryRunRecordsWriteCommands verifica que, em modo dry-run, apenas comandos de leitura são executados
+func TestDryRunRecordsWriteCommands(t *testing.T) {
+	cfg := &config.Config{
+		DestinationBranch: "main",
+		Tag:               "minor",
+		DryRun:            true,
+	}
+
+	// Apenas o comando de leitura está configurado; os demais falhariam se fossem executados
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git describe --tags --abbrev=0 main", []byte("v1.0.0"), nil)
+
+	plan := utils.NewDryRunPlan()
+	manager := NewManagerWithRunner(cfg, utils.NewRecordingCommandRunner(mockRunner, plan))
+	manager.delayTime = 0
+
+	if err := manager.checkoutDestinationBranch(); err != nil {
+		t.Fatalf("checkoutDestinationBranch falhou com erro: %v", err)
+	}
+
+	newTag, err := manager.createVersionTag()
+	if err != nil {
+		t.Fatalf("createVersionTag falhou com erro: %v", err)
+	}
+
+	if newTag != "v1.1.0" {
+		t.Errorf("Tag calculada: esperava 'v1.1.0', obteve '%s'", newTag)
+	}
+
+	expected := []string{"$ git checkout main", "$ git tag -a v1.1.0 -m 'Version v1.1.0'"}
+	steps := plan.Steps()
+	if len(steps) != len(expected) {
+		t.Fatalf("Plano: esperava %v, obteve %v", expected, steps)
+	}
+	for i := range expected {
+		if steps[i] != expected[i] {
+			t.Errorf("Passo %d: esperava '%s', obteve '%s'", i+1, expected[i], steps[i])
+		}
+	}
+}
+
+// TestDryRunFetchesRemote verifica que o dry-run busca o remoto de verdade, mas não atualiza branches locais
+func TestDryRunFetchesRemote(t *testing.T) {
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git fetch origin", []byte(""), nil)
+
+	plan := utils.NewDryRunPlan()
+	runner := utils.NewRecordingCommandRunner(mockRunner, plan)
+
+	if err := utils.NewGitCommands(runner).Fetch("origin"); err != nil {
+		t.Fatalf("Fetch falhou com erro: %v", err)
+	}
+	if _, err := runner.Run("git", "fetch", "origin", "main:main"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if fmt.Sprint(mockRunner.executed) != "[git fetch origin]" {
+		t.Errorf("Esperava executar apenas 'git fetch origin', obteve %v", mockRunner.executed)
+	}
+	if steps := plan.Steps(); fmt.Sprint(steps) != "[$ git fetch origin main:main]" {
+		t.Errorf("Plano: esperava registrar o fetch que altera a branch local, obteve %v", steps)
+	}
+}
+
+// TestExecuteVersionFlowRollback verifica que uma falha no meio do fluxo desfaz as alterações locais
+func TestExecuteVersionFlowRollback(t *testing.T) {
+	cfg := &config.Config{
+		Remote:            "origin",
+		SourceBranch:      "feature",
+		DestinationBranch: "main",
+		Push:              true,
+		Tag:               "minor",
+	}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git status --porcelain", []byte(""), nil)
+	mockRunner.AddMockResult("git symbolic-ref -q HEAD", []byte("refs/heads/feature\n"), nil)
+	mockRunner.AddMockResult("git fetch origin", []byte(""), nil)
+	mockRunner.AddMockResult("git rev-parse --abbrev-ref HEAD", []byte("feature\n"), nil)
+	mockRunner.AddMockResult("git checkout main", []byte(""), nil)
+	mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+	mockRunner.AddMockResult("git merge feature", []byte(""), nil)
+	mockRunner.AddMockResult("git push origin main", []byte(""), nil)
+	mockRunner.AddMockResult("git describe --tags --abbrev=0 main", []byte("v1.0.0"), nil)
+	mockRunner.AddMockResult("git tag -a v1.1.0 -m Version v1.1.0", []byte(""), nil)
+	mockRunner.AddMockResult("git push origin v1.1.0", []byte("rejected"), fmt.Errorf("exit status 1"))
+	mockRunner.AddMockResult("git tag -d v1.1.0", []byte(""), nil)
+	mockRunner.AddMockResult("git reset --hard abc123", []byte(""), nil)
+	mockRunner.AddMockResult("git checkout feature", []byte(""), nil)
+
+	manager := NewManagerWithRunner(cfg, mockRunner)
+	manager.delayTime = 0
+
+	if err := manager.ExecuteVersionFlow(); err == nil {
+		t.Fatal("ExecuteVersionFlow deveria ter falhado no push da tag")
+	}
+
+	expected := []string{"git tag -d v1.1.0", "git reset --hard abc123", "git checkout feature"}
+	executed := mockRunner.executed
+	if len(executed) < len(expected) {
+		t.Fatalf("Comandos executados insuficientes: %v", executed)
+	}
+
+	undone := executed[len(executed)-len(expected):]
+	for i := range expected {
+		if undone[i] != expected[i] {
+			t.Errorf("Rollback passo %d: esperava '%s', obteve '%s'", i+1, expected[i], undone[i])
+		}
+	}
+}
+
+// TestPreflightBlocksUnsafeRepository verifica que o pre-flight recusa um repositório inseguro
+func TestPreflightBlocksUnsafeRepository(t *testing.T) {
+	cfg := &config.Config{
+		Remote:            "origin",
+		SourceBranch:      "feature",
+		DestinationBranch: "main",
+	}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git status --porcelain", []byte(" M main.go\n?? notes.txt\n"), nil)
+	mockRunner.AddMockResult("git rev-parse -q --verify MERGE_HEAD", []byte("abc123\n"), nil)
+	mockRunner.AddMockResult("git symbolic-ref -q HEAD", []byte("refs/heads/feature\n"), nil)
+	mockRunner.AddMockResult("git fetch origin", []byte(""), nil)
+	mockRunner.AddMockResult("git rev-parse -q --verify refs/remotes/origin/main", []byte("def456\n"), nil)
+	mockRunner.AddMockResult("git rev-list --left-right --count main...origin/main", []byte("0\t2\n"), nil)
+
+	manager := NewManagerWithRunner(cfg, mockRunner)
+
+	report := manager.runPreflightChecks()
+	if report.Safe() {
+		t.Fatalf("O relatório deveria indicar repositório inseguro:\n%s", report)
+	}
+
+	blocking := 0
+	for _, issue := range report.Issues {
+		if issue.Blocking {
+			blocking++
+		}
+	}
+
+	// Alterações não commitadas, merge em andamento e main atrás de origin/main
+	if blocking != 3 {
+		t.Errorf("Esperava 3 problemas bloqueantes, obteve %d:\n%s", blocking, report)
+	}
+
+	asked := false
+	manager.SetPreflightConfirm(func(report *PreflightReport) (bool, error) {
+		asked = true
+		return false, nil
+	})
+
+	if err := manager.preflight(); err == nil {
+		t.Error("preflight deveria falhar quando o usuário não confirma")
+	}
+
+	if !asked {
+		t.Error("preflight deveria perguntar se deve continuar")
+	}
+}
+
+// TestReleaseComponentsTagsOnlyChanged verifica que, em um monorepo, só os componentes alterados recebem tag
+func TestReleaseComponentsTagsOnlyChanged(t *testing.T) {
+	cfg := &config.Config{
+		Remote:            "origin",
+		DestinationBranch: "main",
+		Push:              true,
+		Tag:               "minor",
+		Components: []config.Component{
+			{Name: "billing", Path: "services/billing", TagPrefix: "services/billing/v"},
+			{Name: "api", Path: "services/api", TagPrefix: "services/api/v"},
+			{Name: "worker", Path: "services/worker", TagPrefix: "services/worker/v"},
+		},
+	}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git describe --tags --abbrev=0 --match services/billing/v* main", []byte("services/billing/v1.4.0\n"), nil)
+	mockRunner.AddMockResult("git diff --name-only services/billing/v1.4.0 HEAD -- services/billing", []byte("services/billing/main.go\n"), nil)
+	mockRunner.AddMockResult("git tag -a services/billing/v1.5.0 -m Version services/billing/v1.5.0", []byte(""), nil)
+	mockRunner.AddMockResult("git describe --tags --abbrev=0 --match services/api/v* main", []byte("services/api/v2.0.0\n"), nil)
+	mockRunner.AddMockResult("git diff --name-only services/api/v2.0.0 HEAD -- services/api", []byte(""), nil)
+	mockRunner.AddMockResult("git tag -a services/worker/v1.0.0 -m Version services/worker/v1.0.0", []byte(""), nil)
+	mockRunner.AddMockResult("git push origin services/billing/v1.5.0", []byte(""), nil)
+	mockRunner.AddMockResult("git push origin services/worker/v1.0.0", []byte(""), nil)
+	mockRunner.AddMockResult("git push origin main", []byte(""), nil)
+
+	manager := NewManagerWithRunner(cfg, mockRunner)
+	manager.delayTime = 0
+
+	if err := manager.releaseComponents(); err != nil {
+		t.Fatalf("releaseComponents falhou com erro: %v", err)
+	}
+
+	// O worker ainda não tem tags e começa em 1.0.0; a api não mudou e é ignorada
+	for _, cmd := range mockRunner.executed {
+		if cmd == "git tag -a services/api/v2.1.0 -m Version services/api/v2.1.0" {
+			t.Errorf("O componente api não deveria receber tag")
+		}
+	}
+
+	expected := []string{"git push origin services/billing/v1.5.0", "git push origin services/worker/v1.0.0", "git push origin main"}
+	pushed := mockRunner.executed[len(mockRunner.executed)-len(expected):]
+	for i := range expected {
+		if pushed[i] != expected[i] {
+			t.Errorf("Push %d: esperava '%s', obteve '%s'", i+1, expected[i], pushed[i])
+		}
+	}
+}
+
+// TestCommitReleaseBumpsVersionFiles verifica que os arquivos de versão entram no commit de release
+func TestCommitReleaseBumpsVersionFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatalf("Erro ao escrever arquivo de teste: %v", err)
+	}
+
+	cfg := &config.Config{DestinationBranch: "main"}
+	versionFiles := []bump.Spec{{Type: bump.TypeVersion}}
+	path := filepath.Join(dir, "VERSION")
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+	mockRunner.AddMockResult("git add -- "+path, []byte(""), nil)
+	mockRunner.AddMockResult("git commit -m chore(release): release-1.1.0", []byte(""), nil)
+
+	manager := NewManagerWithRunner(cfg, mockRunner)
+
+	if err := manager.commitRelease("release-1.0.0", "release-1.1.0", "release-", dir, versionFiles); err != nil {
+		t.Fatalf("commitRelease falhou com erro: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "1.1.0\n" {
+		t.Errorf("VERSION: esperava '1.1.0', obteve %q", content)
+	}
+}
+
+// TestCreateSignedVersionTag verifica que a tag assinada é verificada antes do push e removida se a assinatura falhar
+func TestCreateSignedVersionTag(t *testing.T) {
+	testCases := []struct {
+		name        string
+		verifyErr   error
+		wantErr     bool
+		lastCommand string
+	}{
+		{"Assinatura Válida", nil, false, "git -c gpg.ssh.allowedSignersFile=.allowed_signers tag -v v1.1.0"},
+		{"Assinatura Inválida", fmt.Errorf("exit status 1"), true, "git tag -d v1.1.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Tag:     "minor",
+				Signing: config.Signing{Tags: true, AllowedSigners: ".allowed_signers"},
+			}
+
+			mockRunner := NewMockCommandRunner()
+			mockRunner.AddMockResult("git describe --tags --abbrev=0", []byte("v1.0.0"), nil)
+			mockRunner.AddMockResult("git config --get gpg.format", []byte("ssh\n"), nil)
+			mockRunner.AddMockResult("git config --get user.signingkey", []byte("~/.ssh/id_ed25519.pub\n"), nil)
+			mockRunner.AddMockResult("git -c gpg.format=ssh tag -u ~/.ssh/id_ed25519.pub -m Version v1.1.0 v1.1.0", []byte(""), nil)
+			mockRunner.AddMockResult("git -c gpg.ssh.allowedSignersFile=.allowed_signers tag -v v1.1.0", []byte("Good \"git\" signature"), tc.verifyErr)
+			mockRunner.AddMockResult("git tag -d v1.1.0", []byte(""), nil)
+
+			manager := NewManagerWithRunner(cfg, mockRunner)
+			manager.delayTime = 0
+
+			_, err := manager.createVersionTag()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			executed := mockRunner.executed
+			if last := executed[len(executed)-1]; last != tc.lastCommand {
+				t.Errorf("Último comando: esperava '%s', obteve '%s'", tc.lastCommand, last)
+			}
+		})
+	}
+}
+
+// TestPreflightRequiresSSHSigningKey verifica que assinar com SSH sem chave bloqueia o fluxo
+func TestPreflightRequiresSSHSigningKey(t *testing.T) {
+	cfg := &config.Config{
+		DestinationBranch: "main",
+		Tag:               "patch",
+		Signing:           config.Signing{Tags: true, Format: config.SigningSSH},
+	}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git status --porcelain", []byte(""), nil)
+	mockRunner.AddMockResult("git symbolic-ref -q HEAD", []byte("refs/heads/main\n"), nil)
+
+	report := NewManagerWithRunner(cfg, mockRunner).runPreflightChecks()
+	if report.Safe() {
+		t.Fatalf("Esperava problema bloqueante de assinatura:\n%s", report)
+	}
+}
+
+// TestCheckGPGSigner verifica que as chaves permitidas são comparadas por inteiro e que IDs curtos são recusados
+func TestCheckGPGSigner(t *testing.T) {
+	status := "[GNUPG:] GOODSIG DEADBEEFCAFE0001 Release Bot\n" +
+		"[GNUPG:] VALIDSIG 0123456789ABCDEF0123456789ABCDEF01234567 2024-01-01 1704067200 0 4 0 1 10 00 AAAABBBBCCCCDDDDEEEEFFFF0000DEADBEEFCAFE0001\n"
+
+	testCases := []struct {
+		name    string
+		allowed string
+		valid   bool
+	}{
+		{"Fingerprint Da Subchave", "0123456789ABCDEF0123456789ABCDEF01234567", true},
+		{"Fingerprint Da Chave Primária", "AAAA BBBB CCCC DDDD EEEE FFFF 0000 DEAD BEEF CAFE 0001", true},
+		{"ID Longo", "0xdeadbeefcafe0001", true},
+		{"ID Longo Da Subchave", "89ABCDEF01234567", true},
+		{"ID Curto", "CAFE0001", false},
+		{"Sufixo Que Não É ID", "0000DEADBEEFCAFE0001", false},
+		{"Outra Chave", "1111222233334444", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkGPGSigner(status, []string{tc.allowed})
+			if (err == nil) != tc.valid {
+				t.Errorf("Esperava válida=%v para %s, obteve %v", tc.valid, tc.allowed, err)
+			}
+		})
+	}
+}
+
+// TestVerifyTags verifica que só as tags de versão do intervalo são verificadas, em ordem
+func TestVerifyTags(t *testing.T) {
+	cfg := &config.Config{
+		Signing: config.Signing{AllowedKeys: []string{"0xDEADBEEFCAFE0001"}},
+	}
+
+	validSig := "[GNUPG:] NEWSIG\n[GNUPG:] GOODSIG DEADBEEFCAFE0001 Release Bot\n" +
+		"[GNUPG:] VALIDSIG 0123456789ABCDEF0123456789ABCDEF01234567 2024-01-01 1704067200 0 4 0 1 10 00 AAAABBBBCCCCDDDDEEEEFFFF0000DEADBEEFCAFE0001\n"
+	otherSig := "[GNUPG:] GOODSIG 1111222233334444 Someone\n"
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git tag -l *", []byte("v1.0.0\nv1.10.0\nv1.2.0\nv1.3.0\nnightly\nv2.0.0\n"), nil)
+	mockRunner.AddMockResult("git cat-file tag v1.2.0", []byte("object abc\n\n-----BEGIN PGP SIGNATURE-----\n"), nil)
+	mockRunner.AddMockResult("git verify-tag --raw v1.2.0", []byte(validSig), nil)
+	mockRunner.AddMockResult("git cat-file tag v1.3.0", []byte("object abc\n\n-----BEGIN PGP SIGNATURE-----\n"), nil)
+	mockRunner.AddMockResult("git verify-tag --raw v1.3.0", []byte(otherSig), nil)
+	mockRunner.AddMockResult("git cat-file tag v1.10.0", []byte("object abc\ntag v1.10.0\n\nVersion v1.10.0\n"), nil)
+
+	results, err := NewManagerWithRunner(cfg, mockRunner).VerifyTags("v1.0.0", "v1.10.0")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := []struct {
+		tag   string
+		valid bool
+	}{{"v1.2.0", true}, {"v1.3.0", false}, {"v1.10.0", false}}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Esperava %d tags, obteve %v", len(expected), results)
+	}
+	for i, e := range expected {
+		if results[i].Tag != e.tag || (results[i].Err == nil) != e.valid {
+			t.Errorf("Tag %d: esperava %s válida=%v, obteve %s %v", i+1, e.tag, e.valid, results[i].Tag, results[i].Err)
+		}
+	}
+}
+
+// TestMergeStrategies verifica os comandos git de cada estratégia de merge
+func TestMergeStrategies(t *testing.T) {
+	testCases := []struct {
+		strategy string
+		commands []string
+	}{
+		{config.MergeDefault, []string{"git merge feature"}},
+		{config.MergeFastForwardOnly, []string{"git merge --ff-only feature"}},
+		{config.MergeNoFastForward, []string{"git merge --no-ff feature"}},
+		{config.MergeSquash, []string{"git merge --squash feature", "git commit -m Squashed merge of feature into main"}},
+		{config.MergeRebase, []string{
+			"git rev-parse --verify feature^{commit}",
+			"git rebase main feature",
+			"git checkout main",
+			"git merge --ff-only feature",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			cfg := &config.Config{
+				SourceBranch:      "feature",
+				DestinationBranch: "main",
+				Merge:             config.Merge{Rules: []config.MergeRule{{Branch: "ma*", Strategy: tc.strategy}}},
+			}
+
+			mockRunner := NewMockCommandRunner()
+			mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+			mockRunner.AddMockResult("git rev-parse --verify feature^{commit}", []byte("fea123\n"), nil)
+			for _, cmd := range tc.commands {
+				mockRunner.AddMockResult(cmd, []byte(""), nil)
+			}
+
+			manager := NewManagerWithRunner(cfg, mockRunner)
+			manager.delayTime = 0
+
+			if err := manager.mergeBranches(false); err != nil {
+				t.Fatalf("mergeBranches falhou com erro: %v", err)
+			}
+
+			executed := mockRunner.executed[1:]
+			if fmt.Sprint(executed) != fmt.Sprint(tc.commands) {
+				t.Errorf("Esperava os comandos %v, obteve %v", tc.commands, executed)
+			}
+			if manager.rebasedSource != (tc.strategy == config.MergeRebase) {
+				t.Errorf("rebasedSource inesperado: %v", manager.rebasedSource)
+			}
+		})
+	}
+}
+
+// TestMergeMessageTemplate verifica a mensagem de merge com a versão e as issues referenciadas
+func TestMergeMessageTemplate(t *testing.T) {
+	cfg := &config.Config{
+		SourceBranch:      "feature/SHOP-12-checkout",
+		DestinationBranch: "main",
+		Tag:               "minor",
+		Merge: config.Merge{
+			Strategy: config.MergeNoFastForward,
+			Message:  "Release {{.Version}}: merge {{.Source}} into {{.Destination}}\n\nIssues: {{join .Issues \", \"}}\n",
+		},
+	}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git describe --tags --abbrev=0 main", []byte("v1.4.2\n"), nil)
+	mockRunner.AddMockResult("git log --format=%H%x1f%s%x1f%b%x1e feature/SHOP-12-checkout ^main --",
+		[]byte("c2\x1ffix: total rounding\x1fRefs SHOP-40, SHOP-12\x1e\nc1\x1ffeat: new checkout\x1fCloses SHOP-31\x1e\n"), nil)
+
+	message, err := NewManagerWithRunner(cfg, mockRunner).mergeMessage(cfg.SourceBranch, cfg.DestinationBranch)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := "Release v1.5.0: merge feature/SHOP-12-checkout into main\n\nIssues: SHOP-12, SHOP-31, SHOP-40"
+	if message != expected {
+		t.Errorf("Mensagem de merge:\nesperava %q\nobteve   %q", expected, message)
+	}
+}
+
+// TestMergeConflictActions verifica as três saídas de um merge com conflitos e o registro no resumo
+func TestMergeConflictActions(t *testing.T) {
+	testCases := []struct {
+		action    string
+		lastCmd   string
+		inSummary string
+	}{
+		{config.ConflictAbort, "git merge --abort", "aborted on conflicts"},
+		{config.ConflictMergetool, "git commit --no-edit", "resolved with mergetool"},
+		{config.ConflictStop, "git diff --name-only -z --diff-filter=U", "merge left in progress"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.action, func(t *testing.T) {
+			cfg := &config.Config{
+				SourceBranch:      "feature",
+				DestinationBranch: "main",
+			}
+
+			mockRunner := NewMockCommandRunner()
+			mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+			mockRunner.AddMockResult("git merge feature", []byte("CONFLICT (content)"), fmt.Errorf("exit status 1"))
+			mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte("go.mod\x00internal/app.go\x00"), nil)
+			mockRunner.AddMockResult("git merge --abort", []byte(""), nil)
+			mockRunner.AddMockResult("git mergetool", []byte(""), nil)
+			mockRunner.AddMockResult("git commit --no-edit", []byte(""), nil)
+
+			manager := NewManagerWithRunner(cfg, mockRunner)
+			manager.delayTime = 0
+
+			var asked *utils.MergeConflictError
+			manager.SetConflictResolver(func(conflict *utils.MergeConflictError) (string, error) {
+				asked = conflict
+				if tc.action == config.ConflictMergetool {
+					// O mergetool resolve todos os conflitos
+					mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte(""), nil)
+				}
+				return tc.action, nil
+			})
+
+			err := manager.mergeBranches(false)
+
+			if asked == nil || fmt.Sprint(asked.Paths) != "[go.mod internal/app.go]" {
+				t.Fatalf("Esperava perguntar com os arquivos em conflito, obteve %+v", asked)
+			}
+
+			switch tc.action {
+			case config.ConflictAbort:
+				var conflict *utils.MergeConflictError
+				if !errors.As(err, &conflict) {
+					t.Errorf("Esperava MergeConflictError, obteve %v", err)
+				}
+			case config.ConflictMergetool:
+				if err != nil {
+					t.Errorf("Erro inesperado: %v", err)
+				}
+			case config.ConflictStop:
+				if !errors.Is(err, ErrMergeInProgress) {
+					t.Errorf("Esperava ErrMergeInProgress, obteve %v", err)
+				}
+			}
+
+			executed := mockRunner.executed
+			if last := executed[len(executed)-1]; last != tc.lastCmd {
+				t.Errorf("Último comando: esperava '%s', obteve '%s'", tc.lastCmd, last)
+			}
+
+			summary := manager.Summary()
+			if len(summary) != 1 || !strings.Contains(summary[0], tc.inSummary) {
+				t.Errorf("Resumo: esperava '%s', obteve %v", tc.inSummary, summary)
+			}
+		})
+	}
+}
+
+// TestConflictedPathsWithSpaces verifica que arquivos em conflito com espaços ou acentos no caminho não são quebrados
+func TestConflictedPathsWithSpaces(t *testing.T) {
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte("docs/release notes.md\x00config/padrões.yml\x00go.mod\x00"), nil)
+
+	paths, err := utils.NewGitCommands(mockRunner).ConflictedPaths()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := []string{"docs/release notes.md", "config/padrões.yml", "go.mod"}
+	if strings.Join(paths, "|") != strings.Join(expected, "|") {
+		t.Errorf("Esperava %q, obteve %q", expected, paths)
+	}
+}
+
+// TestMergeConflictWithoutResolverAborts verifica que, sem ninguém para perguntar, o merge é abortado
+func TestMergeConflictWithoutResolverAborts(t *testing.T) {
+	cfg := &config.Config{SourceBranch: "feature", DestinationBranch: "main"}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+	mockRunner.AddMockResult("git merge feature", []byte("CONFLICT (content)"), fmt.Errorf("exit status 1"))
+	mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte("go.mod\x00"), nil)
+	mockRunner.AddMockResult("git merge --abort", []byte(""), nil)
+
+	manager := NewManagerWithRunner(cfg, mockRunner)
+	manager.delayTime = 0
+
+	var conflict *utils.MergeConflictError
+	if err := manager.mergeBranches(false); !errors.As(err, &conflict) {
+		t.Fatalf("Esperava MergeConflictError, obteve %v", err)
+	}
+	if last := mockRunner.executed[len(mockRunner.executed)-1]; last != "git merge --abort" {
+		t.Errorf("Esperava abortar o merge, último comando: %s", last)
+	}
+}
+
+// TestRebaseConflictActions verifica que um rebase com conflitos segue o mesmo fluxo de um merge
+func TestRebaseConflictActions(t *testing.T) {
+	testCases := []struct {
+		action   string
+		commands []string
+	}{
+		{config.ConflictAbort, []string{"git rebase --abort", "git checkout main"}},
+		{config.ConflictMergetool, []string{"git mergetool", "git diff --name-only -z --diff-filter=U", "git -c core.editor=true rebase --continue", "git checkout main", "git merge --ff-only feature"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.action, func(t *testing.T) {
+			cfg := &config.Config{
+				SourceBranch:      "feature",
+				DestinationBranch: "main",
+				Merge:             config.Merge{Strategy: config.MergeRebase},
+			}
+
+			mockRunner := NewMockCommandRunner()
+			mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+			mockRunner.AddMockResult("git rev-parse --verify feature^{commit}", []byte("fea123\n"), nil)
+			mockRunner.AddMockResult("git rebase main feature", []byte("CONFLICT (content)"), fmt.Errorf("exit status 1"))
+			mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte("go.mod\x00"), nil)
+			mockRunner.AddMockResult("git rebase --abort", []byte(""), nil)
+			mockRunner.AddMockResult("git checkout main", []byte(""), nil)
+			mockRunner.AddMockResult("git -c core.editor=true rebase --continue", []byte(""), nil)
+			mockRunner.AddMockResult("git merge --ff-only feature", []byte(""), nil)
+			mockRunner.AddMockResult("git mergetool", []byte(""), nil)
+
+			manager := NewManagerWithRunner(cfg, mockRunner)
+			manager.delayTime = 0
+			manager.SetConflictResolver(func(conflict *utils.MergeConflictError) (string, error) {
+				if tc.action == config.ConflictMergetool {
+					// O mergetool resolve todos os conflitos
+					mockRunner.AddMockResult("git diff --name-only -z --diff-filter=U", []byte(""), nil)
+				}
+				return tc.action, nil
+			})
+
+			err := manager.mergeBranches(false)
+
+			var conflict *utils.MergeConflictError
+			switch tc.action {
+			case config.ConflictAbort:
+				if !errors.As(err, &conflict) || !conflict.Rebase {
+					t.Errorf("Esperava MergeConflictError de rebase, obteve %v", err)
+				}
+				if manager.rebasedSource {
+					t.Errorf("Não esperava marcar a branch de origem como rebaseada")
+				}
+			case config.ConflictMergetool:
+				if err != nil {
+					t.Errorf("Erro inesperado: %v", err)
+				}
+				if !manager.rebasedSource {
+					t.Errorf("Esperava marcar a branch de origem como rebaseada")
+				}
+			}
+
+			executed := mockRunner.executed[len(mockRunner.executed)-len(tc.commands):]
+			if fmt.Sprint(executed) != fmt.Sprint(tc.commands) {
+				t.Errorf("Esperava os comandos %v, obteve %v", tc.commands, executed)
+			}
+		})
+	}
+}
+
+// fakePullRequester simula o provedor de pull requests, mergeando o pull request após algumas consultas
+type fakePullRequester struct {
+	opened    *release.PullRequest
+	autoMerge string
+	polls     int
+	mergedAt  int
+	state     string
+}
+
+func (f *fakePullRequester) OpenPullRequest(ctx context.Context, pr *release.PullRequest) (*release.PullRequest, error) {
+	f.opened = pr
+	return &release.PullRequest{Number: 12, Source: pr.Source, Target: pr.Target, URL: "https://forge.example.com/pr/12", State: release.PullRequestOpen}, nil
+}
+
+func (f *fakePullRequester) GetPullRequest(ctx context.Context, number int) (*release.PullRequest, error) {
+	f.polls++
+	if f.polls < f.mergedAt {
+		return &release.PullRequest{Number: number, State: release.PullRequestOpen}, nil
+	}
+	return &release.PullRequest{Number: number, State: f.state, MergeCommit: "fedcba9876543210"}, nil
+}
+
+func (f *fakePullRequester) EnableAutoMerge(ctx context.Context, pr *release.PullRequest, method string) error {
+	f.autoMerge = method
+	return nil
+}
+
+// TestMergeThroughPullRequest verifica o envio da origem, a abertura do pull request e a espera pelo merge
+func TestMergeThroughPullRequest(t *testing.T) {
+	pollInterval = 0
+	defer func() { pollInterval = 15 * time.Second }()
+
+	newManager := func(requester *fakePullRequester) (*Manager, *MockCommandRunner) {
+		cfg := &config.Config{
+			Remote:            "origin",
+			SourceBranch:      "feature/SHOP-7-cart",
+			DestinationBranch: "main",
+			Merge:             config.Merge{Strategy: config.MergeSquash},
+			PullRequest:       config.PullRequest{Enabled: true, AutoMerge: true, Wait: true, Timeout: time.Minute},
+		}
+
+		mockRunner := NewMockCommandRunner()
+		mockRunner.AddMockResult("git push origin feature/SHOP-7-cart", []byte(""), nil)
+		mockRunner.AddMockResult("git log --format=%H%x1f%s%x1f%b%x1e feature/SHOP-7-cart ^main --", []byte("0123456789abcdef\x1fAdd cart\x1fRefs SHOP-9\x1e"), nil)
+		mockRunner.AddMockResult("git fetch origin", []byte(""), nil)
+		mockRunner.AddMockResult("git checkout main", []byte(""), nil)
+		mockRunner.AddMockResult("git rev-parse --verify HEAD^{commit}", []byte("abc123\n"), nil)
+		mockRunner.AddMockResult("git merge --ff-only fedcba9876543210", []byte(""), nil)
+
+		manager := NewManagerWithRunner(cfg, mockRunner)
+		manager.delayTime = 0
+		manager.pullRequester = requester
+		return manager, mockRunner
+	}
+
+	requester := &fakePullRequester{mergedAt: 3, state: release.PullRequestMerged}
+	manager, mockRunner := newManager(requester)
+
+	merged, err := manager.mergeThroughPullRequest()
+	if err != nil || !merged {
+		t.Fatalf("Esperava o pull request mergeado, obteve %v (%v)", merged, err)
+	}
+
+	if requester.opened.Title != "Merge feature/SHOP-7-cart into main" || !strings.Contains(requester.opened.Body, "**Issues:** SHOP-7, SHOP-9") ||
+		!strings.Contains(requester.opened.Body, "- 0123456 Add cart") {
+		t.Errorf("Título ou corpo do pull request inesperado: %q\n%s", requester.opened.Title, requester.opened.Body)
+	}
+	if requester.autoMerge != release.MergeMethodSquash || requester.polls != 3 {
+		t.Errorf("Esperava auto-merge squash e 3 consultas, obteve '%s' e %d", requester.autoMerge, requester.polls)
+	}
+	if last := mockRunner.executed[len(mockRunner.executed)-1]; last != "git merge --ff-only fedcba9876543210" {
+		t.Errorf("Esperava avançar o destino até o commit do merge, último comando: %s", last)
+	}
+	if summary := manager.Summary(); len(summary) != 2 || !strings.Contains(summary[1], "merged as fedcba9") {
+		t.Errorf("Resumo inesperado: %v", summary)
+	}
+
+	closed := &fakePullRequester{mergedAt: 1, state: release.PullRequestClosed}
+	manager, _ = newManager(closed)
+	if _, err := manager.mergeThroughPullRequest(); err == nil || !strings.Contains(err.Error(), "closed without being merged") {
+		t.Errorf("Esperava erro para o pull request fechado, obteve %v", err)
+	}
+
+	noWait := &fakePullRequester{}
+	manager, _ = newManager(noWait)
+	manager.config.PullRequest.Wait = false
+	if merged, err := manager.mergeThroughPullRequest(); err != nil || merged || noWait.polls != 0 {
+		t.Errorf("Sem espera, esperava encerrar com o pull request aberto, obteve %v (%v) após %d consultas", merged, err, noWait.polls)
+	}
+}
+
+// TestPromotionPolicy verifica que violações da política bloqueiam a execução antes de qualquer comando, exceto com override
+func TestPromotionPolicy(t *testing.T) {
+	newConfig := func() *config.Config {
+		cfg := config.NewConfig()
+		cfg.SourceBranch = "develop"
+		cfg.DestinationBranch = "main"
+		cfg.Tag = "major"
+		cfg.RemoveBranch = true
+		cfg.Policy = config.Policy{
+			Promotions: []config.PromotionRule{{To: "main", From: []string{"stage"}}},
+			Bumps:      []config.BumpPolicy{{Branch: "main", Allow: []string{"minor", "patch"}}},
+		}
+		return cfg
+	}
+
+	mockRunner := NewMockCommandRunner()
+	manager := NewManagerWithRunner(newConfig(), mockRunner)
+	manager.delayTime = 0
+
+	err := manager.ExecuteVersionFlow()
+	if err == nil {
+		t.Fatal("Esperava que a política bloqueasse a execução")
+	}
+	for _, rule := range []string{"[promotion] main may only receive merges from stage", "[bump] main only allows minor, patch bumps, not major", "[never-delete] develop"} {
+		if !strings.Contains(err.Error(), rule) {
+			t.Errorf("Erro deveria conter '%s', obteve: %v", rule, err)
+		}
+	}
+	if len(mockRunner.executed) != 0 {
+		t.Errorf("Nenhum comando deveria ser executado, obteve %v", mockRunner.executed)
+	}
+
+	allowed := newConfig()
+	allowed.SourceBranch = "stage"
+	allowed.Tag = "minor"
+	allowed.RemoveBranch = false
+	if violations, err := NewManagerWithRunner(allowed, mockRunner).policyViolations(); err != nil || len(violations) != 0 {
+		t.Errorf("Não esperava violações, obteve %v (%v)", violations, err)
+	}
+
+	overridden := newConfig()
+	overridden.Policy.Override = true
+	manager = NewManagerWithRunner(overridden, mockRunner)
+	violations, _ := manager.policyViolations()
+	if err := manager.enforcePolicy(violations...); err != nil {
+		t.Fatalf("Com override, não esperava erro: %v", err)
+	}
+	if summary := manager.Summary(); len(summary) != 3 || !strings.Contains(summary[0], "promotion policy overridden") {
+		t.Errorf("Esperava as três violações no resumo, obteve %v", summary)
+	}
+}
+
+// fakeBranchDeleter registra as branches removidas pela API do provedor
+type fakeBranchDeleter struct {
+	deleted []string
+}
+
+func (f *fakeBranchDeleter) DeleteBranch(ctx context.Context, branch string) error {
+	f.deleted = append(f.deleted, branch)
+	return nil
+}
+
+// TestRemoveSourceBranch verifica que só branches mergeadas são removidas, localmente e no remoto
+func TestRemoveSourceBranch(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ahead     string
+		mergeTree string
+		removed   bool
+	}{
+		{"Merged", "0\t3\n", "", true},
+		{"Squashed", "2\t1\n", "tree-main\n", true},
+		{"Unmerged", "2\t1\n", "tree-other\n", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{SourceBranch: "feature", DestinationBranch: "main", RemoveBranch: true}
+
+			mockRunner := NewMockCommandRunner()
+			mockRunner.AddMockResult("git rev-list --left-right --count feature...main", []byte(tc.ahead), nil)
+			mockRunner.AddMockResult("git merge-tree --write-tree main feature", []byte(tc.mergeTree), nil)
+			mockRunner.AddMockResult("git rev-parse --verify main^{tree}", []byte("tree-main\n"), nil)
+			mockRunner.AddMockResult("git rev-parse --verify feature^{commit}", []byte("0123456789abcdef\n"), nil)
+			mockRunner.AddMockResult("git branch -D feature", []byte(""), nil)
+
+			manager := NewManagerWithRunner(cfg, mockRunner)
+			manager.delayTime = 0
+
+			if err := manager.removeSourceBranch(); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			removed := mockRunner.executed[len(mockRunner.executed)-1] == "git branch -D feature"
+			if removed != tc.removed {
+				t.Errorf("Remoção: esperava %v, obteve %v (comandos: %v)", tc.removed, removed, mockRunner.executed)
+			}
+
+			expected := "kept branch feature"
+			if tc.removed {
+				expected = "removed branch feature at 0123456789abcdef"
+			}
+			if summary := manager.Summary(); len(summary) != 1 || !strings.Contains(summary[0], expected) {
+				t.Errorf("Resumo: esperava '%s', obteve %v", expected, summary)
+			}
+		})
+	}
+}
+
+// TestDeleteProtectedRemoteBranch verifica que, recusado o push, a branch protegida é removida pela API
+func TestDeleteProtectedRemoteBranch(t *testing.T) {
+	cfg := &config.Config{Remote: "origin", SourceBranch: "feature", DestinationBranch: "main", RemoveBranch: true, DeleteRemoteBranch: true}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git rev-list --left-right --count feature...main", []byte("0\t3\n"), nil)
+	mockRunner.AddMockResult("git rev-parse --verify feature^{commit}", []byte("0123456789abcdef\n"), nil)
+	mockRunner.AddMockResult("git branch -D feature", []byte(""), nil)
+	mockRunner.AddMockResult("git rev-parse --verify origin/feature^{commit}", []byte("0123456789abcdef\n"), nil)
+	mockRunner.AddMockResult("git rev-list --left-right --count origin/feature...main", []byte("0\t3\n"), nil)
+	mockRunner.AddMockResult("git push --delete origin feature", []byte("remote: error: GH006: Protected branch update failed for refs/heads/feature.\n"+
+		"To github.com:acme/shop.git\n ! [remote rejected] feature (protected branch hook declined)\n"), fmt.Errorf("exit status 1"))
+
+	deleter := &fakeBranchDeleter{}
+	manager := NewManagerWithRunner(cfg, mockRunner)
+	manager.delayTime = 0
+	manager.branchDeleter = deleter
+
+	if err := manager.removeSourceBranch(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if fmt.Sprint(deleter.deleted) != "[feature]" {
+		t.Errorf("Esperava remover feature pela API, obteve %v", deleter.deleted)
+	}
+	if summary := manager.Summary(); len(summary) != 2 || summary[1] != "deleted branch feature on origin at 0123456789abcdef" {
+		t.Errorf("Resumo inesperado: %v", summary)
+	}
+}
+
+// TestDeleteRemoteBranchRejectedByHook verifica que uma recusa que não é de branch protegida não cai na API,
+// mesmo com "protected" no nome da branch
+func TestDeleteRemoteBranchRejectedByHook(t *testing.T) {
+	branch := "feature/protected-routes"
+	cfg := &config.Config{Remote: "origin", SourceBranch: branch, DestinationBranch: "main", RemoveBranch: true, DeleteRemoteBranch: true}
+
+	mockRunner := NewMockCommandRunner()
+	mockRunner.AddMockResult("git rev-list --left-right --count "+branch+"...main", []byte("0\t3\n"), nil)
+	mockRunner.AddMockResult("git rev-parse --verify "+branch+"^{commit}", []byte("0123456789abcdef\n"), nil)
+	mockRunner.AddMockResult("git branch -D "+branch, []byte(""), nil)
+	mockRunner.AddMockResult("git rev-parse --verify origin/"+branch+"^{commit}", []byte("0123456789abcdef\n"), nil)
+	mockRunner.AddMockResult("git rev-list --left-right --count origin/"+branch+"...main", []byte("0\t3\n"), nil)
+	mockRunner.AddMockResult("git push --delete origin "+branch,
+		[]byte("remote: deletion of feature/protected-routes is not allowed by policy\n ! [remote rejected] feature/protected-routes (pre-receive hook declined)\n"), fmt.Errorf("exit status 1"))
+
+	deleter := &fakeBranchDeleter{}
+	manager := NewManagerWithRunner(cfg, mockRunner)
+	manager.delayTime = 0
+	manager.branchDeleter = deleter
+
+	err := manager.removeSourceBranch()
+
+	var protected *utils.ProtectedBranchError
+	if err == nil || errors.As(err, &protected) {
+		t.Errorf("Esperava o erro do push, obteve %v", err)
+	}
+	if len(deleter.deleted) != 0 {
+		t.Errorf("Não esperava remover pela API, obteve %v", deleter.deleted)
+	}
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+// merge merges source into destination, which is checked out, with strategy.
+// message, when not empty, is the message of the commit the merge creates.
+func (m *Manager) merge(source string, destination string, strategy string, message string) error {
+	var messageOptions []string
+	if message != "" {
+		messageOptions = []string{"-m", message}
+	}
+
+	switch strategy {
+	case config.MergeFastForwardOnly:
+		return m.gitCmd.Merge(source, "--ff-only")
+
+	case config.MergeNoFastForward:
+		return m.gitCmd.Merge(source, append([]string{"--no-ff"}, messageOptions...)...)
+
+	case config.MergeSquash:
+		if err := m.gitCmd.Merge(source, "--squash"); err != nil {
+			return err
+		}
+		return m.gitCmd.Commit(squashMessage(source, destination, message))
+
+	case config.MergeRebase:
+		if err := m.rebaseSource(source, destination); err != nil {
+			return err
+		}
+		return m.gitCmd.Merge(source, "--ff-only")
+
+	default:
+		return m.gitCmd.Merge(source, messageOptions...)
+	}
+}
+
+// squashMessage returns message, or a default one for a squash merge.
+func squashMessage(source string, destination string, message string) string {
+	if message == "" {
+		return fmt.Sprintf("Squashed merge of %s into %s", source, destination)
+	}
+	return message
+}
+
+// rebaseSource rebases source onto destination and checks destination out
+// again. The rollback moves source back to where it was. A rebase stopped on
+// conflicts is left in progress for resolveConflict.
+func (m *Manager) rebaseSource(source string, destination string) error {
+	sourceHead, err := m.gitCmd.RevParse(source)
+	if err != nil {
+		return err
+	}
+
+	if err := m.gitCmd.Rebase(destination, source); err != nil {
+		var conflict *utils.MergeConflictError
+		if errors.As(err, &conflict) {
+			m.recordRebase(source, destination, sourceHead)
+			return err
+		}
+		_ = m.gitCmd.RebaseAbort()
+		_ = m.gitCmd.Checkout(destination)
+		return err
+	}
+
+	m.recordRebase(source, destination, sourceHead)
+	m.rebasedSource = true
+
+	return m.gitCmd.Checkout(destination)
+}
+
+// recordRebase records moving source back to sourceHead in the journal.
+func (m *Manager) recordRebase(source string, destination string, sourceHead string) {
+	m.journal.record(fmt.Sprintf("rebase %s onto %s", source, destination), func() error {
+		return m.gitCmd.MoveBranch(source, sourceHead)
+	})
+}
+
+// continueRebase goes on with a rebase of source onto destination whose
+// conflicts were resolved and fast-forwards destination to it.
+func (m *Manager) continueRebase(source string, destination string) error {
+	if err := m.gitCmd.RebaseContinue(source); err != nil {
+		return err
+	}
+	m.rebasedSource = true
+
+	if err := m.gitCmd.Checkout(destination); err != nil {
+		return err
+	}
+	return m.gitCmd.Merge(source, "--ff-only")
+}
+
+// mergeMessage renders the merge message template configured for destination,
+// or returns "" when there is none.
+func (m *Manager) mergeMessage(source string, destination string) (string, error) {
+	if m.config.MergeMessageFor(destination) == "" {
+		return "", nil
+	}
+
+	data, err := m.mergeMessageData(source, destination)
+	if err != nil {
+		return "", err
+	}
+
+	return m.renderMergeMessage(destination, data)
+}
+
+// renderMergeMessage renders the merge message template configured for
+// destination with data, or returns "" when there is none.
+func (m *Manager) renderMergeMessage(destination string, data config.MergeMessageData) (string, error) {
+	text := m.config.MergeMessageFor(destination)
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := config.ParseMergeMessage(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid merge message template: %v", err)
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("invalid merge message template: %v", err)
+	}
+
+	return strings.TrimSpace(message.String()), nil
+}
+
+// mergeMessageData describes the merge of source into destination. The
+// version is the tag the run is about to create, following the latest tag of
+// the destination.
+func (m *Manager) mergeMessageData(source string, destination string) (config.MergeMessageData, error) {
+	data := config.MergeMessageData{Source: source, Destination: destination}
+
+	if m.config.Tag != "" && len(m.config.Components) == 0 {
+		lastTag, err := m.gitCmd.GetLatestTagFrom(destination, m.config.TagPrefix)
+		if err != nil {
+			lastTag = ""
+		}
+		if data.Version, _, err = m.nextTag(lastTag, m.config.TagPrefix, ""); err != nil {
+			return data, err
+		}
+	}
+
+	issues, err := m.issueKeys(source, destination)
+	if err != nil {
+		return data, err
+	}
+	data.Issues = issues
+
+	return data, nil
+}
+
+// issueKeys returns the issue keys in the name of source and in the commits it
+// adds to destination, in order of appearance and without repetitions.
+func (m *Manager) issueKeys(source string, destination string) ([]string, error) {
+	pattern := m.config.Merge.IssuePattern
+	if pattern == "" {
+		pattern = config.DefaultIssuePattern
+	}
+	issueRegexp, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue pattern %q: %v", pattern, err)
+	}
+
+	commits, err := m.gitCmd.CommitsSinceIn(destination, "", source)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := []string{source}
+	// git log lists the newest commits first.
+	for i := len(commits) - 1; i >= 0; i-- {
+		texts = append(texts, commits[i].Subject, commits[i].Body)
+	}
+
+	var keys []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, key := range issueRegexp.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+// PolicyViolation is a rule of the promotion policy the run would break.
+type PolicyViolation struct {
+	Rule    string
+	Message string
+}
+
+// policyViolations checks the run against the promotion policy: the source
+// allowed into the destination, the bump allowed on it and the branches that
+// must never be deleted. An automatic bump is resolved from the commits first;
+// in a monorepo each component's bump is checked as it is resolved instead.
+func (m *Manager) policyViolations() ([]PolicyViolation, error) {
+	var violations []PolicyViolation
+
+	source, destination := m.config.SourceBranch, m.config.DestinationBranch
+
+	if allowed, restricted := m.config.PromotionSources(destination); restricted && !matchesAny(allowed, source) {
+		violations = append(violations, PolicyViolation{
+			Rule:    "promotion",
+			Message: fmt.Sprintf("%s may only receive merges from %s, not from %s", destination, strings.Join(allowed, ", "), source),
+		})
+	}
+
+	if bump := m.config.Tag; bump != "" && (bump != version.BumpAuto || len(m.config.Components) == 0) {
+		if bump == version.BumpAuto {
+			var err error
+			if bump, err = m.autoBump(); err != nil {
+				return nil, err
+			}
+		}
+		if violation := m.bumpViolation(bump); violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	if m.config.RemoveBranch && m.config.IsProtected(source) {
+		violations = append(violations, PolicyViolation{
+			Rule:    "never-delete",
+			Message: fmt.Sprintf("%s must never be deleted", source),
+		})
+	}
+
+	return violations, nil
+}
+
+// autoBump resolves the automatic bump of the repository as nextTag does.
+func (m *Manager) autoBump() (string, error) {
+	if _, restricted := m.config.AllowedBumps(m.config.DestinationBranch); !restricted {
+		return version.BumpAuto, nil
+	}
+
+	handler, err := newVersionHandler(m.gitCmd, m.config, m.config.TagPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	lastTag, err := m.gitCmd.GetLatestTagFrom(m.config.DestinationBranch, m.config.TagPrefix)
+	if err != nil {
+		lastTag = ""
+	}
+
+	commits, err := m.gitCmd.CommitsSinceIn(lastTag, "", m.config.DestinationBranch, m.config.SourceBranch)
+	if err != nil {
+		return "", err
+	}
+
+	bump, _ := handler.ResolveBump(lastTag, version.BumpAuto, commits)
+	return bump, nil
+}
+
+// bumpViolation reports bump when the destination does not allow it. "auto"
+// in the allowed list accepts whatever bump the commits resolve to.
+func (m *Manager) bumpViolation(bump string) *PolicyViolation {
+	destination := m.config.DestinationBranch
+
+	allowed, restricted := m.config.AllowedBumps(destination)
+	if !restricted {
+		return nil
+	}
+	for _, allowedBump := range allowed {
+		if allowedBump == bump || allowedBump == version.BumpAuto && m.config.Tag == version.BumpAuto {
+			return nil
+		}
+	}
+
+	return &PolicyViolation{
+		Rule:    "bump",
+		Message: fmt.Sprintf("%s only allows %s bumps, not %s", destination, strings.Join(allowed, ", "), bump),
+	}
+}
+
+// enforcePolicy blocks the run on violations, unless the policy is explicitly
+// overridden, in which case each one is logged and kept in the run summary.
+func (m *Manager) enforcePolicy(violations ...PolicyViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if m.config.Policy.Override {
+		for _, violation := range violations {
+			m.logger.Warning("Overriding promotion policy (%s): %s", violation.Rule, violation.Message)
+			m.summarize("promotion policy overridden (%s): %s", violation.Rule, violation.Message)
+		}
+		return nil
+	}
+
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		m.logger.Error("%s: %s", violation.Rule, violation.Message)
+		lines = append(lines, fmt.Sprintf("[%s] %s", violation.Rule, violation.Message))
+	}
+
+	if m.plan != nil {
+		m.logger.Warning("The promotion policy would block this run; continuing only because this is a dry run")
+		return nil
+	}
+
+	return fmt.Errorf("run blocked by the promotion policy (use --override-policy to run anyway):\n%s", strings.Join(lines, "\n"))
+}
+
+// matchesAny reports whether branch matches one of the path.Match patterns.
+func matchesAny(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PreflightIssue is a problem found before the flow touches the repository.
+// Blocking issues make the repository unsafe for the flow; the others are
+// only reported.
+type PreflightIssue struct {
+	Check    string
+	Message  string
+	Blocking bool
+}
+
+// PreflightReport gathers the result of every pre-flight check.
+type PreflightReport struct {
+	Issues []PreflightIssue
+}
+
+func (r *PreflightReport) add(check string, blocking bool, format string, a ...interface{}) {
+	r.Issues = append(r.Issues, PreflightIssue{
+		Check:    check,
+		Message:  fmt.Sprintf(format, a...),
+		Blocking: blocking,
+	})
+}
+
+// Safe reports whether no blocking issue was found.
+func (r *PreflightReport) Safe() bool {
+	for _, issue := range r.Issues {
+		if issue.Blocking {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the report, one issue per line.
+func (r *PreflightReport) String() string {
+	lines := make([]string, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		level := "warning"
+		if issue.Blocking {
+			level = "blocking"
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s", level, issue.Check, issue.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetPreflightConfirm sets the function asked whether to continue when the
+// pre-flight report is unsafe. Without it an unsafe repository stops the flow.
+func (m *Manager) SetPreflightConfirm(confirm func(report *PreflightReport) (bool, error)) {
+	m.preflightConfirm = confirm
+}
+
+// preflight inspects the repository before anything is changed and refuses,
+// or asks, to continue when it is not safe to run the flow.
+func (m *Manager) preflight() error {
+	m.logger.Info("Running pre-flight checks")
+
+	report := m.runPreflightChecks()
+
+	for _, issue := range report.Issues {
+		if issue.Blocking {
+			m.logger.Error("%s: %s", issue.Check, issue.Message)
+		} else {
+			m.logger.Warning("%s: %s", issue.Check, issue.Message)
+		}
+	}
+
+	if report.Safe() {
+		m.logger.Success("Pre-flight checks passed")
+		return nil
+	}
+
+	if m.plan != nil {
+		m.logger.Warning("Repository is not safe for the flow; continuing only because this is a dry run")
+		return nil
+	}
+
+	if m.preflightConfirm != nil {
+		proceed, err := m.preflightConfirm(report)
+		if err != nil {
+			return err
+		}
+		if proceed {
+			m.logger.Warning("Continuing despite failed pre-flight checks")
+			return nil
+		}
+	}
+
+	return fmt.Errorf("pre-flight checks failed, repository is not safe:\n%s", report)
+}
+
+func (m *Manager) runPreflightChecks() *PreflightReport {
+	report := &PreflightReport{}
+
+	m.checkWorkingTree(report)
+	m.checkOperationsInProgress(report)
+	m.checkDetachedHead(report)
+	m.checkSigningKey(report)
+
+	if m.config.Remote == "" {
+		return report
+	}
+
+	if err := m.gitCmd.Fetch(m.config.Remote); err != nil {
+		report.add("fetch", true, "could not fetch %s: %v", m.config.Remote, err)
+		return report
+	}
+
+	m.checkUpstream(report, m.config.SourceBranch)
+	m.checkUpstream(report, m.config.DestinationBranch)
+
+	return report
+}
+
+func (m *Manager) checkWorkingTree(report *PreflightReport) {
+	entries, err := m.gitCmd.Status()
+	if err != nil {
+		report.add("working tree", true, "%v", err)
+		return
+	}
+
+	modified, untracked := 0, 0
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, "??") {
+			untracked++
+		} else {
+			modified++
+		}
+	}
+
+	if modified > 0 {
+		report.add("working tree", true, "%d uncommitted change(s), commit or stash them first", modified)
+	}
+	if untracked > 0 {
+		report.add("working tree", false, "%d untracked file(s)", untracked)
+	}
+}
+
+func (m *Manager) checkOperationsInProgress(report *PreflightReport) {
+	for _, ref := range []string{"MERGE_HEAD", "CHERRY_PICK_HEAD", "REVERT_HEAD"} {
+		if m.gitCmd.RefExists(ref) {
+			report.add("operation in progress", true, "%s exists, finish or abort it first", ref)
+		}
+	}
+
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := m.gitCmd.GitPath(dir)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			report.add("operation in progress", true, "a rebase is in progress, finish or abort it first")
+			return
+		}
+	}
+}
+
+func (m *Manager) checkDetachedHead(report *PreflightReport) {
+	if _, err := m.gitCmd.SymbolicRef("HEAD"); err != nil {
+		report.add("HEAD", true, "HEAD is detached, check out a branch first")
+	}
+}
+
+func (m *Manager) checkUpstream(report *PreflightReport, branch string) {
+	if branch == "" {
+		return
+	}
+
+	remoteBranch := m.config.Remote + "/" + branch
+	if !m.gitCmd.RefExists("refs/remotes/" + remoteBranch) {
+		report.add("upstream", false, "%s does not exist yet", remoteBranch)
+		return
+	}
+
+	ahead, behind, err := m.gitCmd.AheadBehind(branch, remoteBranch)
+	if err != nil {
+		report.add("upstream", true, "could not compare %s with %s: %v", branch, remoteBranch, err)
+		return
+	}
+
+	if behind > 0 {
+		report.add("upstream", true, "%s is %s behind %s, pull it first", branch, commits(behind), remoteBranch)
+	}
+	if ahead > 0 {
+		report.add("upstream", false, "%s is %s ahead of %s", branch, commits(ahead), remoteBranch)
+	}
+}
+
+func commits(n int) string {
+	if n == 1 {
+		return "1 commit"
+	}
+	return strconv.Itoa(n) + " commits"
+}
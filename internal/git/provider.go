@@ -0,0 +1,28 @@
+package git
+
+import (
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/remote"
+)
+
+// DetectProvider returns the release provider of the forge hosting
+// cfg.Remote, using cfg.Hosts for self-hosted instances. It returns "" when
+// the remote URL does not identify one.
+func DetectProvider(gitCmd *utils.GitCommands, cfg *config.Config) string {
+	if cfg.Remote == "" {
+		return ""
+	}
+
+	rawURL, err := gitCmd.RemoteURL(cfg.Remote)
+	if err != nil {
+		return ""
+	}
+
+	repo, err := remote.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return cfg.ProviderFor(repo.Host)
+}
@@ -0,0 +1,214 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/release"
+)
+
+// pollInterval is how often a pull request is checked while waiting for it to
+// be merged. Tests shorten it.
+var pollInterval = 15 * time.Second
+
+// mergeThroughPullRequest pushes the source branch and opens a pull request
+// into the destination instead of merging locally. When configured to wait,
+// it returns once the pull request is merged, with the destination checked out
+// at the merged commit, and reports true; otherwise the run ends with the pull
+// request open.
+func (m *Manager) mergeThroughPullRequest() (bool, error) {
+	source, destination := m.config.SourceBranch, m.config.DestinationBranch
+
+	if m.config.Remote == "" {
+		return false, fmt.Errorf("pull request mode needs a remote to push %s to", source)
+	}
+
+	spinner := utils.NewProgressSpinner(fmt.Sprintf("Pushing %s to %s", source, m.config.Remote))
+	err := spinner.WithDelay(func() error {
+		if err := m.gitCmd.Push(m.config.Remote, source); err != nil {
+			return err
+		}
+		m.journal.recordRemote(fmt.Sprintf("pushed %s to %s", source, m.config.Remote))
+		return nil
+	}, m.delayTime)
+	if err != nil {
+		return false, err
+	}
+
+	requester := m.pullRequester
+	if requester == nil {
+		if requester, err = release.NewReleaseManagerWithTransport(m.config, m.releaseTransport).PullRequests(); err != nil {
+			return false, err
+		}
+	}
+
+	title, body, err := m.pullRequestText(source, destination)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+
+	pr, err := requester.OpenPullRequest(ctx, &release.PullRequest{Source: source, Target: destination, Title: title, Body: body})
+	if err != nil {
+		return false, fmt.Errorf("could not open a pull request from %s into %s: %v", source, destination, err)
+	}
+	m.journal.recordRemote(fmt.Sprintf("opened pull request #%d from %s into %s", pr.Number, source, destination))
+	m.summarize("pull request #%d from %s into %s: %s", pr.Number, source, destination, pr.URL)
+	m.logger.Success("Opened pull request #%d: %s", pr.Number, pr.URL)
+
+	if m.config.PullRequest.AutoMerge {
+		if err := requester.EnableAutoMerge(ctx, pr, autoMergeMethod(m.config.MergeStrategyFor(destination))); err != nil {
+			return false, fmt.Errorf("could not enable auto-merge on pull request #%d: %v", pr.Number, err)
+		}
+		m.logger.Success("Auto-merge enabled on pull request #%d", pr.Number)
+	}
+
+	if !m.config.PullRequest.Wait {
+		if m.config.Tag != "" {
+			m.logger.Warning("Not waiting for pull request #%d to be merged, so no tag was created", pr.Number)
+		}
+		return false, nil
+	}
+
+	mergeCommit := m.config.Remote + "/" + destination
+	if m.plan != nil {
+		m.plan.Add(fmt.Sprintf("wait for pull request #%d to be merged", pr.Number))
+	} else {
+		merged, err := m.waitForMerge(ctx, requester, pr)
+		if err != nil {
+			return false, err
+		}
+		if merged.MergeCommit != "" {
+			mergeCommit = merged.MergeCommit
+		}
+		m.summarize("pull request #%d merged as %s", pr.Number, shortHash(mergeCommit))
+	}
+
+	return true, m.checkoutMergedCommit(mergeCommit)
+}
+
+// waitForMerge polls pr until it is merged, closed or the timeout runs out.
+func (m *Manager) waitForMerge(ctx context.Context, requester release.PullRequester, pr *release.PullRequest) (*release.PullRequest, error) {
+	timeout := m.config.PullRequest.Timeout
+	deadline := time.Now().Add(timeout)
+
+	m.logger.Info("Waiting up to %s for pull request #%d to be merged", timeout, pr.Number)
+
+	for {
+		current, err := requester.GetPullRequest(ctx, pr.Number)
+		if err != nil {
+			return nil, fmt.Errorf("could not check pull request #%d: %v", pr.Number, err)
+		}
+
+		switch current.State {
+		case release.PullRequestMerged:
+			m.logger.Success("Pull request #%d was merged", pr.Number)
+			return current, nil
+		case release.PullRequestClosed:
+			return nil, fmt.Errorf("pull request #%d was closed without being merged", pr.Number)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("pull request #%d was not merged within %s; tag it once merged", pr.Number, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// checkoutMergedCommit fetches the destination and fast-forwards it to the
+// merged commit, where the version is tagged.
+func (m *Manager) checkoutMergedCommit(mergeCommit string) error {
+	destination := m.config.DestinationBranch
+
+	spinner := utils.NewProgressSpinner(fmt.Sprintf("Updating %s to the merged commit", destination))
+	err := spinner.WithDelay(func() error {
+		if err := m.gitCmd.Fetch(m.config.Remote); err != nil {
+			return err
+		}
+		if err := m.checkout(destination); err != nil {
+			return err
+		}
+
+		previousHead, err := m.gitCmd.RevParse("HEAD")
+		if err != nil {
+			return err
+		}
+		m.journal.record(fmt.Sprintf("fast-forward %s to %s", destination, mergeCommit), func() error {
+			return m.gitCmd.ResetHard(previousHead)
+		})
+
+		return m.gitCmd.Merge(mergeCommit, "--ff-only")
+	}, m.delayTime)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Success("Updated %s to the merged commit", destination)
+	return nil
+}
+
+// pullRequestText returns the title and body of the pull request from source
+// into destination. The title is the first line of the merge message, when a
+// template is configured, and its other lines open the body.
+func (m *Manager) pullRequestText(source string, destination string) (string, string, error) {
+	data, err := m.mergeMessageData(source, destination)
+	if err != nil {
+		return "", "", err
+	}
+
+	title := fmt.Sprintf("Merge %s into %s", source, destination)
+	if data.Version != "" {
+		title = fmt.Sprintf("Release %s: merge %s into %s", data.Version, source, destination)
+	}
+
+	var body strings.Builder
+
+	message, err := m.renderMergeMessage(destination, data)
+	if err != nil {
+		return "", "", err
+	}
+	if message != "" {
+		first, rest, _ := strings.Cut(message, "\n")
+		title = first
+		if rest = strings.TrimSpace(rest); rest != "" {
+			body.WriteString(rest + "\n\n")
+		}
+	}
+
+	fmt.Fprintf(&body, "Merges `%s` into `%s`.\n", source, destination)
+	if data.Version != "" {
+		fmt.Fprintf(&body, "\n**Version:** %s\n", data.Version)
+	}
+	if len(data.Issues) > 0 {
+		fmt.Fprintf(&body, "\n**Issues:** %s\n", strings.Join(data.Issues, ", "))
+	}
+
+	commits, err := m.gitCmd.CommitsSince(destination, source)
+	if err != nil {
+		return "", "", err
+	}
+	if len(commits) > 0 {
+		body.WriteString("\n**Commits:**\n\n")
+		for _, commit := range commits {
+			fmt.Fprintf(&body, "- %s %s\n", shortHash(commit.Hash), commit.Subject)
+		}
+	}
+
+	return title, body.String(), nil
+}
+
+// autoMergeMethod returns the auto-merge method matching a merge strategy.
+func autoMergeMethod(strategy string) string {
+	switch strategy {
+	case config.MergeSquash:
+		return release.MergeMethodSquash
+	case config.MergeRebase:
+		return release.MergeMethodRebase
+	}
+	return release.MergeMethodMerge
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/be-tech/version-manager/pkg/bump"
+)
+
+// commitRelease writes the changelog and the version files for newTag and
+// commits them as "chore(release): <tag>", before the tag is created. Nothing
+// is committed when neither is configured.
+func (m *Manager) commitRelease(lastTag string, newTag string, prefix string, dir string, versionFiles []bump.Spec) error {
+	if m.config.ChangelogFile == "" && len(versionFiles) == 0 {
+		return nil
+	}
+
+	previousHead, err := m.gitCmd.RevParse("HEAD")
+	if err != nil {
+		return err
+	}
+
+	// Undoes the edits and, once it exists, the release commit.
+	m.journal.record("release commit", func() error {
+		return m.gitCmd.ResetHard(previousHead)
+	})
+
+	var paths []string
+
+	changelogPath, err := m.writeChangelog(lastTag, newTag, dir)
+	if err != nil {
+		return err
+	}
+	if changelogPath != "" {
+		paths = append(paths, changelogPath)
+	}
+
+	bumped, err := m.bumpVersionFiles(strings.TrimPrefix(strings.TrimPrefix(newTag, prefix), "v"), dir, versionFiles)
+	if err != nil {
+		return err
+	}
+	paths = append(paths, bumped...)
+
+	if err := m.gitCmd.Add(paths...); err != nil {
+		return err
+	}
+
+	return m.gitCmd.Commit(fmt.Sprintf("chore(release): %s", newTag))
+}
+
+// bumpVersionFiles writes newVersion into every version file and returns the
+// paths it changed.
+func (m *Manager) bumpVersionFiles(newVersion string, dir string, versionFiles []bump.Spec) ([]string, error) {
+	var paths []string
+
+	for _, spec := range versionFiles {
+		bumper, err := bump.New(spec, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		files := bumper.Files()
+
+		if m.plan != nil {
+			m.plan.Add(fmt.Sprintf("set version %s in %s", newVersion, strings.Join(files, ", ")))
+		} else if err := bumper.Bump(newVersion); err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, files...)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+// signingKey returns the format and key that sign release tags: the ones in
+// the tool config, or else git's gpg.format and user.signingkey.
+func (m *Manager) signingKey() (string, string, error) {
+	format := m.config.Signing.Format
+	if format == "" {
+		format = config.SigningGPG
+		if m.gitCmd.ConfigValue("gpg.format") == "ssh" {
+			format = config.SigningSSH
+		}
+	}
+
+	key := m.config.Signing.Key
+	if key == "" {
+		key = m.gitCmd.ConfigValue("user.signingkey")
+	}
+
+	// gpg falls back to the key of the committer's e-mail, ssh has no default.
+	if key == "" && format == config.SigningSSH {
+		return "", "", fmt.Errorf("no SSH signing key configured, set signing.key or git config user.signingkey")
+	}
+
+	return format, key, nil
+}
+
+// createTag creates the annotated tag of a release, signed and verified when
+// signing is enabled, so that a tag that does not verify is never pushed.
+func (m *Manager) createTag(tag string) error {
+	message := fmt.Sprintf("Version %s", tag)
+
+	if !m.config.Signing.Tags {
+		return m.gitCmd.CreateTag(tag, message)
+	}
+
+	format, key, err := m.signingKey()
+	if err != nil {
+		return err
+	}
+
+	if err := m.gitCmd.CreateSignedTag(tag, message, format, key); err != nil {
+		return err
+	}
+
+	if m.plan != nil {
+		m.plan.Add(fmt.Sprintf("verify the signature of tag %s", tag))
+		return nil
+	}
+
+	if _, err := m.gitCmd.VerifyTag(tag, m.config.Signing.AllowedSigners); err != nil {
+		// The tag is not journaled yet, so the rollback would not remove it.
+		_ = m.gitCmd.DeleteTag(tag)
+		return fmt.Errorf("tag %s was signed but does not verify: %v", tag, err)
+	}
+
+	return nil
+}
+
+// checkSigningKey reports a missing signing key before anything is changed.
+func (m *Manager) checkSigningKey(report *PreflightReport) {
+	if !m.config.Signing.Tags || m.config.Tag == "" {
+		return
+	}
+	if _, _, err := m.signingKey(); err != nil {
+		report.add("signing", true, "%v", err)
+	}
+}
+
+// TagVerification is the result of checking the signature of one tag.
+type TagVerification struct {
+	Tag string
+	Err error
+}
+
+// VerifyTags checks that every version tag after from, up to and including
+// to, is signed by an allowed key: one in the SSH allowed signers file, or
+// one of the allowed GPG keys. Empty bounds are open. It returns the result
+// of each tag, in version order.
+func (m *Manager) VerifyTags(from string, to string) ([]TagVerification, error) {
+	tags, err := m.versionTags(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TagVerification, 0, len(tags))
+	for _, tag := range tags {
+		results = append(results, TagVerification{Tag: tag, Err: m.VerifyTag(tag)})
+	}
+	return results, nil
+}
+
+// versionTags returns the tags with the configured prefix whose versions are
+// greater than from and not greater than to, sorted by version.
+func (m *Manager) versionTags(from string, to string) ([]string, error) {
+	prefix := m.config.TagPrefix
+
+	parse := func(tag string) (*version.Version, error) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), prefix)
+		return version.Parse(strings.TrimPrefix(tag, "v"))
+	}
+
+	var lower, upper *version.Version
+	var err error
+	if from != "" {
+		if lower, err = parse(from); err != nil {
+			return nil, fmt.Errorf("invalid range start %q: %v", from, err)
+		}
+	}
+	if to != "" {
+		if upper, err = parse(to); err != nil {
+			return nil, fmt.Errorf("invalid range end %q: %v", to, err)
+		}
+	}
+
+	tags, err := m.gitCmd.ListTags(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	type versionTag struct {
+		tag     string
+		version *version.Version
+	}
+	var selected []versionTag
+
+	for _, tag := range tags {
+		v, err := parse(tag)
+		if err != nil {
+			continue
+		}
+		if lower != nil && v.Compare(lower) <= 0 || upper != nil && v.Compare(upper) > 0 {
+			continue
+		}
+		selected = append(selected, versionTag{tag: strings.TrimSpace(tag), version: v})
+	}
+
+	sort.Slice(selected, func(i, j int) bool { return selected[i].version.Compare(selected[j].version) < 0 })
+
+	result := make([]string, 0, len(selected))
+	for _, s := range selected {
+		result = append(result, s.tag)
+	}
+	return result, nil
+}
+
+// VerifyTag checks that tag has a valid signature by an allowed key.
+func (m *Manager) VerifyTag(tag string) error {
+	object, err := m.gitCmd.TagObject(tag)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.Contains(object, "-----BEGIN SSH SIGNATURE-----"):
+		// git checks the signer against the allowed signers file itself.
+		if m.config.Signing.AllowedSigners == "" && m.gitCmd.ConfigValue("gpg.ssh.allowedSignersFile") == "" {
+			return fmt.Errorf("no allowed signers file configured, set signing.allowed_signers or git config gpg.ssh.allowedSignersFile")
+		}
+		_, err := m.gitCmd.VerifyTag(tag, m.config.Signing.AllowedSigners)
+		return err
+
+	case strings.Contains(object, "-----BEGIN PGP SIGNATURE-----"):
+		if len(m.config.Signing.AllowedKeys) == 0 {
+			return fmt.Errorf("no allowed GPG keys configured, set signing.allowed_keys")
+		}
+		status, err := m.gitCmd.VerifyTagRaw(tag, "")
+		if err != nil {
+			return err
+		}
+		return checkGPGSigner(status, m.config.Signing.AllowedKeys)
+
+	default:
+		return fmt.Errorf("tag is not signed")
+	}
+}
+
+// longKeyIDLength is the number of hex digits of a long GPG key ID.
+const longKeyIDLength = 16
+
+// checkGPGSigner checks that the raw GPG status of a good signature names one
+// of allowed, given as fingerprints or long key IDs of 16 hex digits, by the
+// signing key or its primary key. Keys are matched exactly; short key IDs are
+// rejected, as they are easily forged.
+func checkGPGSigner(status string, allowed []string) error {
+	var keys []string
+
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "[GNUPG:] "))
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "VALIDSIG":
+			keys = append(keys, fields[1])
+			// The primary key fingerprint is the last field.
+			if len(fields) >= 11 {
+				keys = append(keys, fields[len(fields)-1])
+			}
+		case "GOODSIG":
+			keys = append(keys, fields[1])
+		}
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("no good GPG signature found")
+	}
+
+	allowedKeys := make([]string, 0, len(allowed))
+	for _, allowedKey := range allowed {
+		allowedKey = strings.TrimPrefix(strings.ToUpper(strings.ReplaceAll(allowedKey, " ", "")), "0X")
+		if len(allowedKey) < longKeyIDLength {
+			return fmt.Errorf("allowed GPG key %s is too short, use its fingerprint or its long key ID", allowedKey)
+		}
+		allowedKeys = append(allowedKeys, allowedKey)
+	}
+
+	for _, allowedKey := range allowedKeys {
+		for _, key := range keys {
+			key = strings.ToUpper(key)
+			if key == allowedKey {
+				return nil
+			}
+			// The long key ID of a fingerprint is its last 16 hex digits.
+			if len(allowedKey) == longKeyIDLength && len(key) > longKeyIDLength && key[len(key)-longKeyIDLength:] == allowedKey {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("signed by %s, which is not an allowed key", keys[0])
+}
@@ -0,0 +1,31 @@
+package git
+
+import (
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+// NewVersionHandler returns the version handler for cfg's destination branch.
+// On a prerelease channel it also loads the existing tags, so that the new
+// prerelease counter continues from the highest one already published.
+func NewVersionHandler(gitCmd *utils.GitCommands, cfg *config.Config) (*version.Handler, error) {
+	return newVersionHandler(gitCmd, cfg, cfg.TagPrefix)
+}
+
+func newVersionHandler(gitCmd *utils.GitCommands, cfg *config.Config, prefix string) (*version.Handler, error) {
+	channel := cfg.ChannelFor(cfg.DestinationBranch)
+	if channel == "" {
+		return version.NewHandlerWithPrefix(prefix), nil
+	}
+
+	handler := version.NewHandlerWithChannel(prefix, channel)
+
+	tags, err := gitCmd.ListTags(prefix)
+	if err != nil {
+		return nil, err
+	}
+	handler.SetExistingTags(tags)
+
+	return handler, nil
+}
@@ -6,15 +6,18 @@ import (
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/be-tech/version-manager/internal/git"
 	"github.com/be-tech/version-manager/internal/utils"
 	"github.com/be-tech/version-manager/pkg/config"
 	"github.com/be-tech/version-manager/pkg/version"
 )
 
 type UI struct {
-	config *config.Config
-	logger *utils.Logger
-	gitCmd *utils.GitCommands
+	config      *config.Config
+	logger      utils.Logger
+	gitCmd      *utils.GitCommands
+	provided    map[string]bool
+	interactive bool
 }
 
 type DefaultCommandRunner struct{}
@@ -30,10 +33,23 @@ func (r *DefaultCommandRunner) Output(name string, args ...string) ([]byte, erro
 }
 
 func NewUI() *UI {
+	return NewUIWithConfig(config.NewConfig(), nil, true)
+}
+
+// NewUIWithConfig creates a UI that only asks for the values not listed in
+// provided. When interactive is false no prompt is shown: optional values keep
+// their defaults and missing required values produce an error.
+func NewUIWithConfig(cfg *config.Config, provided map[string]bool, interactive bool) *UI {
+	if provided == nil {
+		provided = make(map[string]bool)
+	}
+
 	return &UI{
-		config: config.NewConfig(),
-		logger: utils.NewLogger(),
-		gitCmd: utils.NewGitCommands(&DefaultCommandRunner{}),
+		config:      cfg,
+		logger:      utils.NewLogger(),
+		gitCmd:      utils.NewGitCommands(&DefaultCommandRunner{}),
+		provided:    provided,
+		interactive: interactive,
 	}
 }
 
@@ -87,6 +103,10 @@ func (u *UI) logChoice(description string, choice interface{}) {
 	u.logger.Info("%s: %v", description, choice)
 }
 
+func (u *UI) missingValue(flag string) error {
+	return fmt.Errorf("valor obrigatório não informado: use --%s (sessão não interativa)", flag)
+}
+
 func (u *UI) selectOrigin() error {
 	remotes, err := u.gitCmd.GetRemotes()
 	if err != nil {
@@ -97,6 +117,23 @@ func (u *UI) selectOrigin() error {
 		return fmt.Errorf("nenhum repositório remoto encontrado")
 	}
 
+	if u.provided["remote"] {
+		if !contains(remotes, u.config.Remote) {
+			return fmt.Errorf("repositório remoto não encontrado: %s", u.config.Remote)
+		}
+		u.logChoice("Repositório remoto selecionado", u.config.Remote)
+		return nil
+	}
+
+	if !u.interactive {
+		if len(remotes) > 1 {
+			return u.missingValue("remote")
+		}
+		u.config.Remote = remotes[0]
+		u.logChoice("Repositório remoto selecionado", u.config.Remote)
+		return nil
+	}
+
 	prompt := &survey.Select{
 		Message: "Qual repositório remoto você deseja usar?",
 		Options: remotes,
@@ -119,6 +156,18 @@ func (u *UI) selectSourceBranch() error {
 		return err
 	}
 
+	if u.provided["from"] {
+		if !contains(branches, u.config.SourceBranch) {
+			return fmt.Errorf("branch de origem não encontrada: %s", u.config.SourceBranch)
+		}
+		u.logChoice("Branch de origem selecionada", u.config.SourceBranch)
+		return nil
+	}
+
+	if !u.interactive {
+		return u.missingValue("from")
+	}
+
 	prompt := &survey.Select{
 		Message: "Qual branch de origem você deseja mesclar na branch de destino?",
 		Options: branches,
@@ -152,6 +201,18 @@ func (u *UI) selectDestinationBranch() error {
 		return fmt.Errorf("nenhuma outra branch encontrada")
 	}
 
+	if u.provided["to"] {
+		if !contains(filteredBranches, u.config.DestinationBranch) {
+			return fmt.Errorf("branch de destino não encontrada: %s", u.config.DestinationBranch)
+		}
+		u.logChoice("Branch de destino selecionada", u.config.DestinationBranch)
+		return nil
+	}
+
+	if !u.interactive {
+		return u.missingValue("to")
+	}
+
 	prompt := &survey.Select{
 		Message: "Qual branch de destino você deseja para mesclar sua branch de origem?",
 		Options: filteredBranches,
@@ -169,6 +230,11 @@ func (u *UI) selectDestinationBranch() error {
 }
 
 func (u *UI) askWantsPush() error {
+	if u.provided["push"] || !u.interactive {
+		u.logChoice("Enviará para o repositório remoto", u.config.Push)
+		return nil
+	}
+
 	prompt := &survey.Confirm{
 		Message: "Você deseja enviar as alterações para o repositório remoto?",
 		Default: false,
@@ -186,12 +252,19 @@ func (u *UI) askWantsPush() error {
 }
 
 func (u *UI) askRemoveFromBranch() error {
-	protectedBranches := []string{"master", "main", "develop", "stage"}
-	for _, protected := range protectedBranches {
-		if u.config.SourceBranch == protected {
-			u.config.RemoveBranch = false
-			return nil
+	// With the policy overridden, an explicit --remove-branch is left for the
+	// manager to log.
+	if u.config.IsProtected(u.config.SourceBranch) && !u.config.Policy.Override {
+		if u.config.RemoveBranch {
+			u.logger.Warning("A branch %s é protegida e não será removida", u.config.SourceBranch)
 		}
+		u.config.RemoveBranch = false
+		return nil
+	}
+
+	if u.provided["remove-branch"] || !u.interactive {
+		u.logChoice("Removerá a branch de origem", u.config.RemoveBranch)
+		return u.askDeleteRemoteBranch()
 	}
 
 	prompt := &survey.Confirm{
@@ -207,19 +280,66 @@ func (u *UI) askRemoveFromBranch() error {
 	u.config.RemoveBranch = remove
 	u.logChoice("Removerá a branch de origem", u.config.RemoveBranch)
 
+	return u.askDeleteRemoteBranch()
+}
+
+func (u *UI) askDeleteRemoteBranch() error {
+	if !u.config.RemoveBranch || u.config.Remote == "" {
+		return nil
+	}
+
+	if u.provided["delete-remote"] || !u.interactive {
+		u.logChoice("Removerá a branch de origem no remoto", u.config.DeleteRemoteBranch)
+		return nil
+	}
+
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Você deseja remover a branch de origem também em %s?", u.config.Remote),
+		Default: u.config.DeleteRemoteBranch,
+	}
+
+	var remove bool
+	if err := survey.AskOne(prompt, &remove); err != nil {
+		return fmt.Errorf("falha ao obter confirmação para remover branch remota: %v", err)
+	}
+
+	u.config.DeleteRemoteBranch = remove
+	u.logChoice("Removerá a branch de origem no remoto", u.config.DeleteRemoteBranch)
+
 	return nil
 }
 
 func (u *UI) handleVersionTag() error {
 	if !u.config.Push {
+		if u.config.Tag != "" {
+			u.logger.Warning("Tags só são criadas quando as alterações são enviadas (--push); ignorando --bump")
+		}
 		u.config.Tag = ""
 		return nil
 	}
 
-	output, err := u.gitCmd.GetLatestTag()
+	if u.provided["bump"] {
+		u.logChoice("Versão de lançamento selecionada", u.config.Tag)
+		return nil
+	}
+
+	suggested := u.config.BumpFor(u.config.DestinationBranch)
+
+	if !u.interactive {
+		u.config.Tag = suggested
+		if suggested != "" {
+			u.logChoice("Versão de lançamento sugerida pela configuração", u.config.Tag)
+		}
+		return nil
+	}
+
+	output, err := u.gitCmd.GetLatestTagFrom(u.config.DestinationBranch, u.config.TagPrefix)
 	hasExistingTags := err == nil && output != ""
 
-	if !hasExistingTags {
+	// Components are versioned separately; one without tags starts at 1.0.0 on its own.
+	monorepo := len(u.config.Components) > 0
+
+	if !hasExistingTags && !monorepo {
 		u.config.Tag = "1.0.0"
 		u.logChoice("Tag de versão inicial", u.config.Tag)
 		return nil
@@ -227,14 +347,18 @@ func (u *UI) handleVersionTag() error {
 
 	var options []string
 
-	isStage := u.config.DestinationBranch == "stage"
+	channel := u.config.ChannelFor(u.config.DestinationBranch)
 
-	if isStage {
+	if (suggested == "" || suggested == version.BumpAuto) && !monorepo {
+		suggested = u.inferBump(output)
+	}
+
+	if channel != "" {
 		options = []string{
-			"premajor - versão antes de um lançamento principal que ainda está em desenvolvimento. vX.x.x-x",
-			"preminor - versão antes de um lançamento secundário que ainda está em desenvolvimento. vx.X.x-x",
-			"prepatch - versão antes de um lançamento de correção que ainda está em desenvolvimento. vx.x.X-x",
-			"prerelease - versão antes de um lançamento estável que ainda está em desenvolvimento. vx.x.x-X",
+			fmt.Sprintf("premajor - versão antes de um lançamento principal que ainda está em desenvolvimento. vX.x.x-%s.x", channel),
+			fmt.Sprintf("preminor - versão antes de um lançamento secundário que ainda está em desenvolvimento. vx.X.x-%s.x", channel),
+			fmt.Sprintf("prepatch - versão antes de um lançamento de correção que ainda está em desenvolvimento. vx.x.X-%s.x", channel),
+			fmt.Sprintf("prerelease - versão antes de um lançamento estável que ainda está em desenvolvimento. vx.x.x-%s.X", channel),
 			"Not a version - Selecione para não criar uma tag",
 		}
 	} else {
@@ -251,6 +375,16 @@ func (u *UI) handleVersionTag() error {
 		Options: options,
 	}
 
+	for _, option := range options {
+		if suggested != "" && strings.HasPrefix(option, suggested+" ") {
+			prompt.Default = option
+		}
+	}
+
+	if suggested != "" {
+		prompt.Help = "A opção pré-selecionada foi sugerida pela configuração ou pelos Conventional Commits."
+	}
+
 	var versionChoice string
 	if err := survey.AskOne(prompt, &versionChoice); err != nil {
 		return fmt.Errorf("falha ao obter seleção de versão: %v", err)
@@ -270,7 +404,43 @@ func (u *UI) handleVersionTag() error {
 	return nil
 }
 
+// inferBump suggests a version type from the Conventional Commits that are
+// about to be released and shows the commits behind the suggestion. On a
+// prerelease channel the suggestion starts or continues a prerelease.
+func (u *UI) inferBump(lastTag string) string {
+	commits, err := u.gitCmd.CommitsSince(lastTag, u.config.DestinationBranch, u.config.SourceBranch)
+	if err != nil {
+		return ""
+	}
+
+	handler, err := git.NewVersionHandler(u.gitCmd, u.config)
+	if err != nil {
+		return ""
+	}
+
+	bump, drivers := handler.InferBump(commits)
+	if bump == "" {
+		return ""
+	}
+
+	u.logger.Info("Conventional Commits desde %s sugerem uma versão %s:", lastTag, bump)
+	for _, commit := range drivers {
+		hash := commit.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		u.logger.Info("  %s %s", hash, commit.Subject)
+	}
+
+	return handler.ChannelBump(lastTag, bump)
+}
+
 func (u *UI) askCreateRelease() error {
+	if u.provided["release"] || !u.interactive {
+		u.logChoice("Criar release", u.config.CreateRelease)
+		return nil
+	}
+
 	prompt := &survey.Confirm{
 		Message: "Você deseja criar uma release no GitHub/GitLab com esta tag?",
 		Default: false,
@@ -288,9 +458,24 @@ func (u *UI) askCreateRelease() error {
 }
 
 func (u *UI) selectRepoType() error {
+	if u.config.RepoType != "" {
+		u.logChoice("Tipo de repositório", u.config.RepoType)
+		return nil
+	}
+
+	if provider := git.DetectProvider(u.gitCmd, u.config); provider != "" {
+		u.config.RepoType = provider
+		u.logChoice("Tipo de repositório detectado pela URL do remoto", u.config.RepoType)
+		return nil
+	}
+
+	if !u.interactive {
+		return u.missingValue("release")
+	}
+
 	prompt := &survey.Select{
 		Message: "Qual é o tipo do seu repositório?",
-		Options: []string{"GitHub", "GitLab"},
+		Options: []string{"GitHub", "GitLab", "Gitea", "Forgejo", "Bitbucket", "Bitbucket Server"},
 	}
 
 	var repoType string
@@ -298,51 +483,62 @@ func (u *UI) selectRepoType() error {
 		return fmt.Errorf("falha na seleção do tipo de repositório: %v", err)
 	}
 
-	u.config.RepoType = strings.ToLower(repoType)
+	u.config.RepoType = strings.ReplaceAll(strings.ToLower(repoType), " ", "-")
 	u.logChoice("Tipo de repositório", u.config.RepoType)
 
 	return nil
 }
 
 func (u *UI) collectReleaseInfo() error {
-	titlePrompt := &survey.Input{
-		Message: "Título da release (deixe em branco para usar a tag):",
+	if len(u.config.Components) > 0 {
+		u.logger.Info("Cada componente alterado terá a sua release, com título e notas gerados a partir dos commits no seu caminho")
+		return nil
 	}
 
-	var title string
-	if err := survey.AskOne(titlePrompt, &title); err != nil {
-		return fmt.Errorf("falha ao obter título da release: %v", err)
+	title := u.config.ReleaseTitle
+	if !u.provided["release-title"] && u.interactive {
+		titlePrompt := &survey.Input{
+			Message: "Título da release (deixe em branco para usar a tag):",
+		}
+
+		if err := survey.AskOne(titlePrompt, &title); err != nil {
+			return fmt.Errorf("falha ao obter título da release: %v", err)
+		}
 	}
 
+	lastTag, nextTag, versionErr := u.nextVersion()
+
 	if title == "" {
-		// Get the actual tag version that will be created/updated
-		output, err := u.gitCmd.GetLatestTag()
-		if err != nil {
-			// If we couldn't get the latest tag, use a default format
+		if versionErr != nil {
+			// Fallback to simple format if generation fails
 			title = "Release v" + u.config.Tag
 		} else {
-			// Generate the new tag based on the latest tag and version type
-			handler := version.NewHandler()
-			actualVersion, err := handler.GenerateNewTag(output, u.config.Tag)
-			if err != nil {
-				// Fallback to simple format if generation fails
-				title = "Release v" + u.config.Tag
-			} else {
-				title = "Release " + actualVersion
-			}
+			title = "Release " + nextTag
 		}
 	}
 
 	u.config.ReleaseTitle = title
 	u.logChoice("Título da release", u.config.ReleaseTitle)
 
-	notesPrompt := &survey.Multiline{
-		Message: "Notas da release (descrição das mudanças):",
+	notes := u.config.ReleaseNotes
+	if !u.provided["release-notes"] && versionErr == nil {
+		generated, err := git.GenerateReleaseNotes(u.gitCmd, u.config, lastTag, nextTag)
+		if err != nil {
+			u.logger.Warning("Não foi possível gerar as notas a partir do histórico: %v", err)
+		} else {
+			notes = generated
+		}
 	}
 
-	var notes string
-	if err := survey.AskOne(notesPrompt, &notes); err != nil {
-		return fmt.Errorf("falha ao obter notas da release: %v", err)
+	if !u.provided["release-notes"] && u.interactive {
+		notesPrompt := &survey.Multiline{
+			Message: "Notas da release (descrição das mudanças):",
+			Default: notes,
+		}
+
+		if err := survey.AskOne(notesPrompt, &notes); err != nil {
+			return fmt.Errorf("falha ao obter notas da release: %v", err)
+		}
 	}
 
 	u.config.ReleaseNotes = notes
@@ -350,3 +546,98 @@ func (u *UI) collectReleaseInfo() error {
 
 	return nil
 }
+
+// nextVersion returns the latest tag and the tag the flow is about to create.
+func (u *UI) nextVersion() (string, string, error) {
+	lastTag, err := u.gitCmd.GetLatestTagFrom(u.config.DestinationBranch, u.config.TagPrefix)
+	if err != nil {
+		lastTag = ""
+	}
+
+	handler, err := git.NewVersionHandler(u.gitCmd, u.config)
+	if err != nil {
+		return "", "", err
+	}
+
+	bump := u.config.Tag
+	if bump == version.BumpAuto {
+		commits, _ := u.gitCmd.CommitsSince(lastTag, u.config.DestinationBranch, u.config.SourceBranch)
+		bump, _ = handler.ResolveBump(lastTag, bump, commits)
+	}
+
+	nextTag, err := handler.GenerateNewTag(lastTag, bump)
+	if err != nil {
+		return "", "", err
+	}
+	return lastTag, nextTag, nil
+}
+
+// ConfirmContinue asks whether to go on after the problems in details were
+// found. It answers no without asking in a non-interactive session.
+func (u *UI) ConfirmContinue(details string) (bool, error) {
+	if !u.interactive {
+		return false, nil
+	}
+
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("%s\nO repositório não está em um estado seguro. Deseja continuar mesmo assim?", details),
+		Default: false,
+	}
+
+	var proceed bool
+	if err := survey.AskOne(prompt, &proceed); err != nil {
+		return false, fmt.Errorf("falha ao obter confirmação para continuar: %v", err)
+	}
+
+	return proceed, nil
+}
+
+// ChooseConflictAction asks what to do with a merge that stopped on conflict
+// and returns one of config.ConflictAbort, ConflictMergetool or ConflictStop.
+// It aborts without asking in a non-interactive session.
+func (u *UI) ChooseConflictAction(conflict *utils.MergeConflictError) (string, error) {
+	if !u.interactive {
+		return config.ConflictAbort, nil
+	}
+
+	actions := []struct {
+		label  string
+		action string
+	}{
+		{"Abortar o merge e voltar para a branch inicial", config.ConflictAbort},
+		{"Resolver com o mergetool e continuar", config.ConflictMergetool},
+		{"Parar e deixar o merge em andamento para resolução manual", config.ConflictStop},
+	}
+
+	labels := make([]string, 0, len(actions))
+	for _, a := range actions {
+		labels = append(labels, a.label)
+	}
+
+	operation := "merge"
+	if conflict.Rebase {
+		operation = "rebase"
+	}
+
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("O %s da branch %s parou em conflitos (%s). O que deseja fazer?", operation, conflict.Source, strings.Join(conflict.Paths, ", ")),
+		Options: labels,
+		Default: labels[0],
+	}
+
+	var choice int
+	if err := survey.AskOne(prompt, &choice); err != nil {
+		return "", fmt.Errorf("falha ao obter a ação para o conflito: %v", err)
+	}
+
+	return actions[choice].action, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
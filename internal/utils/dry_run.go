@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+)
+
+// DryRunPlan collects, in order, the actions a dry run would have performed.
+type DryRunPlan struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func NewDryRunPlan() *DryRunPlan {
+	return &DryRunPlan{}
+}
+
+func (p *DryRunPlan) Add(step string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = append(p.steps, step)
+}
+
+func (p *DryRunPlan) Steps() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.steps...)
+}
+
+// RecordingCommandRunner runs read-only git commands for real, so the plan is
+// computed from the actual repository state, and records every other command
+// in the plan instead of running it. git fetch runs for real as well, as it
+// only updates remote-tracking refs, so the plan sees the remote as it is.
+type RecordingCommandRunner struct {
+	runner CommandRunner
+	plan   *DryRunPlan
+}
+
+func NewRecordingCommandRunner(runner CommandRunner, plan *DryRunPlan) *RecordingCommandRunner {
+	return &RecordingCommandRunner{
+		runner: runner,
+		plan:   plan,
+	}
+}
+
+func (r *RecordingCommandRunner) Run(name string, args ...string) ([]byte, error) {
+	if isReadOnlyCommand(name, args) {
+		return r.runner.Run(name, args...)
+	}
+
+	r.plan.Add("$ " + formatCommand(name, args))
+	return []byte{}, nil
+}
+
+func (r *RecordingCommandRunner) Output(name string, args ...string) ([]byte, error) {
+	if isReadOnlyCommand(name, args) {
+		return r.runner.Output(name, args...)
+	}
+
+	r.plan.Add("$ " + formatCommand(name, args))
+	return []byte{}, nil
+}
+
+var readOnlyGitCommands = map[string]bool{
+	"describe":     true,
+	"rev-parse":    true,
+	"rev-list":     true,
+	"log":          true,
+	"status":       true,
+	"show":         true,
+	"diff":         true,
+	"merge-base":   true,
+	"merge-tree":   true,
+	"for-each-ref": true,
+	"ls-remote":    true,
+	"cat-file":     true,
+	"symbolic-ref": true,
+	"verify-tag":   true,
+}
+
+func isReadOnlyCommand(name string, args []string) bool {
+	if name != "git" || len(args) == 0 {
+		return false
+	}
+
+	// Global options such as "-c key=value" come before the subcommand.
+	for len(args) > 1 && args[0] == "-c" {
+		args = args[2:]
+	}
+
+	subcommand, rest := args[0], args[1:]
+	if readOnlyGitCommands[subcommand] {
+		return true
+	}
+
+	switch subcommand {
+	case "remote":
+		return len(rest) == 0 || rest[0] == "-v" || rest[0] == "get-url" || rest[0] == "show"
+	case "branch":
+		return !hasAnyArg(rest, "-d", "-D", "--delete", "-m", "-M", "--move", "-c", "-C", "--copy",
+			"-u", "--set-upstream-to", "--unset-upstream", "-f", "--force") &&
+			(len(rest) == 0 || hasAnyArg(rest, "--list", "-l", "--show-current", "--merged", "--contains", "-a", "-r") ||
+				allFlags(rest))
+	case "tag":
+		return len(rest) == 0 || hasAnyArg(rest, "-l", "--list", "-v", "--verify", "--contains", "--points-at", "--merged")
+	case "config":
+		return hasAnyArg(rest, "--get", "--get-all", "--get-regexp", "-l", "--list")
+	case "fetch":
+		// A refspec such as main:main would update a local branch.
+		for _, arg := range rest {
+			if !strings.HasPrefix(arg, "-") && strings.Contains(arg, ":") {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func hasAnyArg(args []string, candidates ...string) bool {
+	for _, arg := range args {
+		for _, candidate := range candidates {
+			if arg == candidate || strings.HasPrefix(arg, candidate+"=") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allFlags(args []string) bool {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+func formatCommand(name string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, name)
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'$") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/be-tech/version-manager/pkg/version"
 )
 
 type GitCommands struct {
@@ -14,9 +17,17 @@ type CommandRunner interface {
 	Output(name string, args ...string) ([]byte, error)
 }
 
+// InteractiveRunner is a CommandRunner that can also run a command attached to
+// the terminal, for tools that talk to the user such as git mergetool.
+type InteractiveRunner interface {
+	RunInteractive(name string, args ...string) error
+}
+
+// NewGitCommands returns the git commands run through runner, which are
+// logged at debug level.
 func NewGitCommands(runner CommandRunner) *GitCommands {
 	return &GitCommands{
-		runner: runner,
+		runner: NewLoggingCommandRunner(runner, NewLogger()),
 	}
 }
 
@@ -28,14 +39,137 @@ func (g *GitCommands) Checkout(branch string) error {
 	return nil
 }
 
-func (g *GitCommands) Merge(sourceBranch string) error {
-	output, err := g.runner.Run("git", "merge", sourceBranch)
+// Merge merges sourceBranch into the current branch, with options such as
+// "--no-ff" or "-m <message>" placed before it.
+func (g *GitCommands) Merge(sourceBranch string, options ...string) error {
+	args := append(append([]string{"merge"}, options...), sourceBranch)
+	output, err := g.runner.Run("git", args...)
 	if err != nil {
+		if paths, _ := g.ConflictedPaths(); len(paths) > 0 {
+			return &MergeConflictError{Source: sourceBranch, Paths: paths, Output: string(output)}
+		}
 		return fmt.Errorf("erro ao fazer merge da branch %s: %v\n%s", sourceBranch, err, output)
 	}
 	return nil
 }
 
+// MergeConflictError is returned by Merge and Rebase when they stop on
+// conflicts, leaving the repository in the middle of the merge or rebase.
+type MergeConflictError struct {
+	Source string
+	// Paths are the files with unresolved conflicts.
+	Paths  []string
+	Output string
+	// Rebase is set when a rebase, not a merge, stopped on the conflicts.
+	Rebase bool
+}
+
+func (e *MergeConflictError) Error() string {
+	operation := "merge"
+	if e.Rebase {
+		operation = "rebase"
+	}
+	return fmt.Sprintf("conflito no %s da branch %s em %d arquivo(s): %s", operation, e.Source, len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// ConflictedPaths returns the files with unresolved merge conflicts.
+func (g *GitCommands) ConflictedPaths() ([]string, error) {
+	// -z keeps paths with spaces or non-ASCII characters as they are.
+	output, err := g.runner.Output("git", "diff", "--name-only", "-z", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos em conflito: %v", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// MergeAbort stops the merge in progress and restores the branch.
+func (g *GitCommands) MergeAbort() error {
+	output, err := g.runner.Run("git", "merge", "--abort")
+	if err != nil {
+		return fmt.Errorf("erro ao abortar o merge: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// MergeTool runs the configured git mergetool on the conflicting files,
+// attached to the terminal when the runner supports it.
+func (g *GitCommands) MergeTool() error {
+	if runner, ok := g.runner.(InteractiveRunner); ok {
+		if err := runner.RunInteractive("git", "mergetool"); err != nil {
+			return fmt.Errorf("erro ao executar o mergetool: %v", err)
+		}
+		return nil
+	}
+
+	output, err := g.runner.Run("git", "mergetool")
+	if err != nil {
+		return fmt.Errorf("erro ao executar o mergetool: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// CommitNoEdit commits the staged changes with the prepared message, e.g. the
+// one of a merge that stopped on conflicts.
+func (g *GitCommands) CommitNoEdit() error {
+	output, err := g.runner.Run("git", "commit", "--no-edit")
+	if err != nil {
+		return fmt.Errorf("erro ao criar commit: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// Rebase replays the commits of branch on top of upstream, leaving branch
+// checked out.
+func (g *GitCommands) Rebase(upstream string, branch string) error {
+	output, err := g.runner.Run("git", "rebase", upstream, branch)
+	if err != nil {
+		if paths, _ := g.ConflictedPaths(); len(paths) > 0 {
+			return &MergeConflictError{Source: branch, Paths: paths, Output: string(output), Rebase: true}
+		}
+		return fmt.Errorf("erro ao fazer rebase da branch %s sobre %s: %v\n%s", branch, upstream, err, output)
+	}
+	return nil
+}
+
+// RebaseContinue goes on with a rebase whose conflicts were resolved, keeping
+// the message of each commit. It stops on the conflicts of a later commit.
+func (g *GitCommands) RebaseContinue(branch string) error {
+	output, err := g.runner.Run("git", "-c", "core.editor=true", "rebase", "--continue")
+	if err != nil {
+		if paths, _ := g.ConflictedPaths(); len(paths) > 0 {
+			return &MergeConflictError{Source: branch, Paths: paths, Output: string(output), Rebase: true}
+		}
+		return fmt.Errorf("erro ao continuar o rebase da branch %s: %v\n%s", branch, err, output)
+	}
+	return nil
+}
+
+// RebaseAbort stops a rebase in progress and restores the branch.
+func (g *GitCommands) RebaseAbort() error {
+	output, err := g.runner.Run("git", "rebase", "--abort")
+	if err != nil {
+		return fmt.Errorf("erro ao abortar o rebase: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// PushForceWithLease pushes branch, replacing it on remote only if it is
+// still where the remote-tracking branch says, e.g. after a rebase.
+func (g *GitCommands) PushForceWithLease(remote string, branch string) error {
+	output, err := g.runner.Run("git", "push", "--force-with-lease", remote, branch)
+	if err != nil {
+		return fmt.Errorf("erro ao fazer push forçado da branch %s para %s: %v\n%s", branch, remote, err, output)
+	}
+	return nil
+}
+
 func (g *GitCommands) Push(remote string, branch string) error {
 	output, err := g.runner.Run("git", "push", remote, branch)
 	if err != nil {
@@ -52,6 +186,70 @@ func (g *GitCommands) RemoveBranch(branch string) error {
 	return nil
 }
 
+// DeleteRemoteBranch deletes branch on remote. A branch the remote refuses to
+// delete because it is protected returns a *ProtectedBranchError.
+func (g *GitCommands) DeleteRemoteBranch(remote string, branch string) error {
+	output, err := g.runner.Run("git", "push", "--delete", remote, branch)
+	if err != nil {
+		if protectedBranchRejection(string(output), branch) {
+			return &ProtectedBranchError{Remote: remote, Branch: branch, Output: string(output)}
+		}
+		return fmt.Errorf("erro ao remover a branch %s de %s: %v\n%s", branch, remote, err, output)
+	}
+	return nil
+}
+
+// protectedBranchRejection reports whether the output of a push names branch
+// as rejected for being protected: GitHub declines it with its protected
+// branch hook, GitLab with its own message. Other rejections, such as those
+// of a pre-receive hook, are not.
+func protectedBranchRejection(output string, branch string) bool {
+	rejected := fmt.Sprintf("! [remote rejected] %s (protected branch hook declined)", branch)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == rejected || strings.HasPrefix(line, "remote: GitLab: You can only delete protected branches") {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedBranchError is returned by DeleteRemoteBranch when the remote
+// rejects the deletion of a protected branch.
+type ProtectedBranchError struct {
+	Remote string
+	Branch string
+	Output string
+}
+
+func (e *ProtectedBranchError) Error() string {
+	return fmt.Sprintf("a branch %s é protegida em %s e não pode ser removida por push\n%s", e.Branch, e.Remote, e.Output)
+}
+
+// MergeTree returns the tree that merging theirs into ours would produce,
+// without touching the working tree or any branch.
+func (g *GitCommands) MergeTree(ours string, theirs string) (string, error) {
+	output, err := g.runner.Output("git", "merge-tree", "--write-tree", ours, theirs)
+	if err != nil {
+		return "", fmt.Errorf("erro ao simular o merge de %s em %s: %v", theirs, ours, err)
+	}
+	// Conflicts add more lines after the tree.
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("saída inesperada de git merge-tree: %q", output)
+	}
+	return fields[0], nil
+}
+
+// Tree returns the tree of ref.
+func (g *GitCommands) Tree(ref string) (string, error) {
+	output, err := g.runner.Output("git", "rev-parse", "--verify", ref+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("erro ao resolver a árvore de %s: %v", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func (g *GitCommands) CreateTag(tag string, message string) error {
 	output, err := g.runner.Run("git", "tag", "-a", tag, "-m", message)
 	if err != nil {
@@ -60,6 +258,84 @@ func (g *GitCommands) CreateTag(tag string, message string) error {
 	return nil
 }
 
+// CreateSignedTag creates an annotated tag signed in format, "gpg" or "ssh",
+// with key. An empty format or key uses git's gpg.format or user.signingkey.
+func (g *GitCommands) CreateSignedTag(tag string, message string, format string, key string) error {
+	args := signingConfigArgs(format, "")
+	args = append(args, "tag")
+	if key != "" {
+		args = append(args, "-u", key)
+	} else {
+		args = append(args, "-s")
+	}
+	args = append(args, "-m", message, tag)
+
+	output, err := g.runner.Run("git", args...)
+	if err != nil {
+		return fmt.Errorf("erro ao criar a tag assinada %s: %v\n%s", tag, err, output)
+	}
+	return nil
+}
+
+// VerifyTag checks the signature of tag with "git tag -v". SSH signatures
+// are checked against allowedSigners, or git's gpg.ssh.allowedSignersFile.
+func (g *GitCommands) VerifyTag(tag string, allowedSigners string) (string, error) {
+	args := append(signingConfigArgs("", allowedSigners), "tag", "-v", tag)
+
+	output, err := g.runner.Run("git", args...)
+	if err != nil {
+		return string(output), fmt.Errorf("assinatura da tag %s inválida: %v\n%s", tag, err, output)
+	}
+	return string(output), nil
+}
+
+// VerifyTagRaw is like VerifyTag but returns the raw GPG status lines, which
+// include the signing key's fingerprint.
+func (g *GitCommands) VerifyTagRaw(tag string, allowedSigners string) (string, error) {
+	args := append(signingConfigArgs("", allowedSigners), "verify-tag", "--raw", tag)
+
+	output, err := g.runner.Run("git", args...)
+	if err != nil {
+		return string(output), fmt.Errorf("assinatura da tag %s inválida: %v\n%s", tag, err, output)
+	}
+	return string(output), nil
+}
+
+// TagObject returns the raw content of the annotated tag object, including
+// its signature, if any.
+func (g *GitCommands) TagObject(tag string) (string, error) {
+	output, err := g.runner.Output("git", "cat-file", "tag", tag)
+	if err != nil {
+		return "", fmt.Errorf("%s não é uma tag anotada: %v", tag, err)
+	}
+	return string(output), nil
+}
+
+// ConfigValue returns the value of a git config key, or "" when it is unset.
+func (g *GitCommands) ConfigValue(key string) string {
+	output, err := g.runner.Output("git", "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// signingConfigArgs returns the "-c" options selecting the signing format and
+// the SSH allowed signers file, when given.
+func signingConfigArgs(format string, allowedSigners string) []string {
+	var args []string
+	switch format {
+	case "gpg":
+		args = append(args, "-c", "gpg.format=openpgp")
+	case "ssh":
+		args = append(args, "-c", "gpg.format=ssh")
+	}
+	if allowedSigners != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSigners)
+	}
+	return args
+}
+
 func (g *GitCommands) GetLatestTag() (string, error) {
 	output, err := g.runner.Output("git", "describe", "--tags", "--abbrev=0")
 	if err != nil {
@@ -68,6 +344,48 @@ func (g *GitCommands) GetLatestTag() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetLatestTagWithPrefix returns the most recent tag reachable from HEAD whose
+// name starts with prefix. An empty prefix behaves like GetLatestTag.
+func (g *GitCommands) GetLatestTagWithPrefix(prefix string) (string, error) {
+	return g.GetLatestTagFrom("", prefix)
+}
+
+// GetLatestTagFrom is like GetLatestTagWithPrefix but starts from ref instead
+// of HEAD. An empty ref means HEAD.
+func (g *GitCommands) GetLatestTagFrom(ref string, prefix string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if prefix != "" {
+		args = append(args, "--match", prefix+"*")
+	}
+	if ref != "" {
+		args = append(args, ref)
+	}
+
+	output, err := g.runner.Output("git", args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ChangedFiles returns the files under dir that differ between from and to.
+func (g *GitCommands) ChangedFiles(from string, to string, dir string) ([]string, error) {
+	output, err := g.runner.Output("git", "diff", "--name-only", from, to, "--", dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos alterados desde %s: %v", from, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// ListTags returns every tag whose name starts with prefix.
+func (g *GitCommands) ListTags(prefix string) ([]string, error) {
+	output, err := g.runner.Output("git", "tag", "-l", prefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar tags: %v", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
 func (g *GitCommands) PushTag(remote string, tag string) error {
 	output, err := g.runner.Run("git", "push", remote, tag)
 	if err != nil {
@@ -76,6 +394,196 @@ func (g *GitCommands) PushTag(remote string, tag string) error {
 	return nil
 }
 
+func (g *GitCommands) DeleteTag(tag string) error {
+	output, err := g.runner.Run("git", "tag", "-d", tag)
+	if err != nil {
+		return fmt.Errorf("erro ao remover a tag %s: %v\n%s", tag, err, output)
+	}
+	return nil
+}
+
+// CurrentBranch returns the checked out branch, or the commit hash when HEAD is detached.
+func (g *GitCommands) CurrentBranch() (string, error) {
+	output, err := g.runner.Output("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("erro ao obter a branch atual: %v", err)
+	}
+
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		return g.RevParse("HEAD")
+	}
+	return branch, nil
+}
+
+// RevParse resolves ref to a commit hash.
+func (g *GitCommands) RevParse(ref string) (string, error) {
+	output, err := g.runner.Output("git", "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("erro ao resolver a referência %s: %v", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResetHard moves the current branch to ref, discarding any merge in progress.
+func (g *GitCommands) ResetHard(ref string) error {
+	output, err := g.runner.Run("git", "reset", "--hard", ref)
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar a branch para %s: %v\n%s", ref, err, output)
+	}
+	return nil
+}
+
+// CreateBranch creates branch pointing at ref without checking it out.
+func (g *GitCommands) CreateBranch(branch string, ref string) error {
+	output, err := g.runner.Run("git", "branch", branch, ref)
+	if err != nil {
+		return fmt.Errorf("erro ao criar a branch %s em %s: %v\n%s", branch, ref, err, output)
+	}
+	return nil
+}
+
+// MoveBranch points branch, which must not be checked out, at ref.
+func (g *GitCommands) MoveBranch(branch string, ref string) error {
+	output, err := g.runner.Run("git", "branch", "-f", branch, ref)
+	if err != nil {
+		return fmt.Errorf("erro ao mover a branch %s para %s: %v\n%s", branch, ref, err, output)
+	}
+	return nil
+}
+
+func (g *GitCommands) Fetch(remote string) error {
+	output, err := g.runner.Run("git", "fetch", remote)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar atualizações de %s: %v\n%s", remote, err, output)
+	}
+	return nil
+}
+
+// Status returns the porcelain status lines of the working tree.
+func (g *GitCommands) Status() ([]string, error) {
+	output, err := g.runner.Output("git", "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar o estado do repositório: %v", err)
+	}
+	return filterEmptyStrings(strings.Split(string(output), "\n")), nil
+}
+
+// RefExists reports whether ref resolves to an object.
+func (g *GitCommands) RefExists(ref string) bool {
+	_, err := g.runner.Output("git", "rev-parse", "-q", "--verify", ref)
+	return err == nil
+}
+
+// GitPath returns the path of name inside the .git directory.
+func (g *GitCommands) GitPath(name string) (string, error) {
+	output, err := g.runner.Output("git", "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("erro ao localizar %s no diretório .git: %v", name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SymbolicRef returns the branch ref that name points to; it fails when HEAD is detached.
+func (g *GitCommands) SymbolicRef(name string) (string, error) {
+	output, err := g.runner.Output("git", "symbolic-ref", "-q", name)
+	if err != nil {
+		return "", fmt.Errorf("%s não aponta para uma branch: %v", name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AheadBehind counts the commits in local that are not in upstream (ahead)
+// and the commits in upstream that are not in local (behind).
+func (g *GitCommands) AheadBehind(local string, upstream string) (int, int, error) {
+	output, err := g.runner.Output("git", "rev-list", "--left-right", "--count", local+"..."+upstream)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao comparar %s com %s: %v", local, upstream, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("saída inesperada de git rev-list: %q", output)
+	}
+
+	ahead, errAhead := strconv.Atoi(fields[0])
+	behind, errBehind := strconv.Atoi(fields[1])
+	if errAhead != nil || errBehind != nil {
+		return 0, 0, fmt.Errorf("saída inesperada de git rev-list: %q", output)
+	}
+	return ahead, behind, nil
+}
+
+// CommitsSince returns the commits reachable from refs but not from tag,
+// newest first. An empty tag returns the whole history of refs.
+func (g *GitCommands) CommitsSince(tag string, refs ...string) ([]version.Commit, error) {
+	return g.CommitsSinceIn(tag, "", refs...)
+}
+
+// CommitsSinceIn is like CommitsSince but only returns the commits touching
+// files under dir. An empty dir means the whole repository.
+func (g *GitCommands) CommitsSinceIn(tag string, dir string, refs ...string) ([]version.Commit, error) {
+	args := []string{"log", "--format=%H%x1f%s%x1f%b%x1e"}
+	for _, ref := range refs {
+		if ref != "" {
+			args = append(args, ref)
+		}
+	}
+	if tag != "" {
+		args = append(args, "^"+tag)
+	}
+	args = append(args, "--")
+	if dir != "" {
+		args = append(args, dir)
+	}
+
+	output, err := g.runner.Output("git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar commits desde %s: %v", tag, err)
+	}
+
+	var commits []version.Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 3)
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+
+		commit := version.Commit{Hash: fields[0], Subject: fields[1]}
+		if len(fields) == 3 {
+			commit.Body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+func (g *GitCommands) RemoteURL(remote string) (string, error) {
+	output, err := g.runner.Output("git", "remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("erro ao obter a URL do remoto %s: %v", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (g *GitCommands) Add(paths ...string) error {
+	args := append([]string{"add", "--"}, paths...)
+	output, err := g.runner.Run("git", args...)
+	if err != nil {
+		return fmt.Errorf("erro ao adicionar %s: %v\n%s", strings.Join(paths, ", "), err, output)
+	}
+	return nil
+}
+
+func (g *GitCommands) Commit(message string) error {
+	output, err := g.runner.Run("git", "commit", "-m", message)
+	if err != nil {
+		return fmt.Errorf("erro ao criar commit: %v\n%s", err, output)
+	}
+	return nil
+}
+
 func (g *GitCommands) GetRemotes() ([]string, error) {
 	output, err := g.runner.Output("git", "remote")
 	if err != nil {
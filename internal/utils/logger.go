@@ -1,38 +1,306 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 )
 
-type Logger struct{}
+// Level is the severity of a log message. Messages below the level of a
+// logger are dropped.
+type Level int
 
-func NewLogger() *Logger {
-	return &Logger{}
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "info"
+}
+
+// Log formats accepted by NewLoggerFor.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LogFormats lists the accepted log formats.
+var LogFormats = []string{LogFormatText, LogFormatJSON}
+
+// Logger reports the progress of the tool. Success and Title are info
+// messages with their own presentation; errors go to stderr.
+type Logger interface {
+	Debug(format string, a ...interface{})
+	Info(format string, a ...interface{})
+	Success(format string, a ...interface{})
+	Title(format string, a ...interface{})
+	Warning(format string, a ...interface{})
+	Error(format string, a ...interface{})
+
+	// Level returns the lowest level the logger writes.
+	Level() Level
+}
+
+var (
+	defaultLoggerMu sync.RWMutex
+	defaultLogger   Logger = NewTextLogger(os.Stdout, os.Stderr, LevelInfo, ColorEnabled())
+)
+
+// NewLogger returns the logger configured for the process with
+// SetDefaultLogger, a colored text logger at info level by default.
+func NewLogger() Logger {
+	defaultLoggerMu.RLock()
+	defer defaultLoggerMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefaultLogger makes l the logger returned by NewLogger. It must be called
+// before the loggers of the tool are created.
+func SetDefaultLogger(l Logger) {
+	defaultLoggerMu.Lock()
+	defer defaultLoggerMu.Unlock()
+	defaultLogger = l
+}
+
+// NewLoggerFor returns the logger of format writing messages from level on to
+// stdout, and errors to stderr.
+func NewLoggerFor(format string, level Level) (Logger, error) {
+	switch format {
+	case LogFormatText, "":
+		return NewTextLogger(os.Stdout, os.Stderr, level, ColorEnabled()), nil
+	case LogFormatJSON:
+		return NewJSONLogger(os.Stdout, os.Stderr, level), nil
+	}
+	return nil, fmt.Errorf("formato de log inválido %q (esperado: %s)", format, strings.Join(LogFormats, ", "))
+}
+
+// ColorEnabled reports whether the output may be colored: not when NO_COLOR
+// is set (https://no-color.org) or stdout is not a terminal.
+func ColorEnabled() bool {
+	return os.Getenv("NO_COLOR") == "" && !color.NoColor
+}
+
+// TextLogger writes one line per message, prefixed by a symbol that is
+// colored when color is enabled.
+type TextLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	err   io.Writer
+	level Level
+	color bool
+}
+
+// NewTextLogger returns a TextLogger writing errors to errOut and the other
+// messages from level on to out.
+func NewTextLogger(out io.Writer, errOut io.Writer, level Level, useColor bool) *TextLogger {
+	return &TextLogger{out: out, err: errOut, level: level, color: useColor}
+}
+
+func (l *TextLogger) Level() Level {
+	return l.level
+}
+
+func (l *TextLogger) Debug(format string, a ...interface{}) {
+	l.print(LevelDebug, "·", color.New(color.FgHiBlack), format, a...)
+}
+
+func (l *TextLogger) Info(format string, a ...interface{}) {
+	l.print(LevelInfo, "ℹ", color.New(color.FgHiBlue), format, a...)
+}
+
+func (l *TextLogger) Success(format string, a ...interface{}) {
+	l.print(LevelInfo, "✓", color.New(color.FgHiGreen), format, a...)
+}
+
+func (l *TextLogger) Title(format string, a ...interface{}) {
+	l.print(LevelInfo, "", color.New(color.FgHiGreen, color.Bold), format, a...)
+}
+
+func (l *TextLogger) Warning(format string, a ...interface{}) {
+	l.print(LevelWarn, "⚠", color.New(color.FgHiYellow), format, a...)
+}
+
+func (l *TextLogger) Error(format string, a ...interface{}) {
+	l.print(LevelError, "✗", color.New(color.FgHiRed), format, a...)
 }
 
-func (l *Logger) Success(format string, a ...interface{}) {
-	green := color.New(color.FgHiGreen).SprintFunc()
-	fmt.Printf("%s %s\n", green("✓"), fmt.Sprintf(format, a...))
+// print writes the message with symbol colored by c; a message without a
+// symbol, a title, is colored as a whole.
+func (l *TextLogger) print(level Level, symbol string, c *color.Color, format string, a ...interface{}) {
+	if level < l.level {
+		return
+	}
+
+	if l.color {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+
+	message := fmt.Sprintf(format, a...)
+	line := c.Sprint(message)
+	if symbol != "" {
+		line = c.Sprint(symbol) + " " + message
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level == LevelError {
+		fmt.Fprintln(l.err, line)
+		return
+	}
+	fmt.Fprintln(l.out, line)
+}
+
+// JSONLogger writes one JSON object per line, with the time, level and
+// message, and a kind of "success" or "title" for those messages.
+type JSONLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	err   io.Writer
+	level Level
+	now   func() time.Time
+}
+
+// NewJSONLogger returns a JSONLogger writing errors to errOut and the other
+// messages from level on to out.
+func NewJSONLogger(out io.Writer, errOut io.Writer, level Level) *JSONLogger {
+	return &JSONLogger{out: out, err: errOut, level: level, now: time.Now}
+}
+
+type jsonEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+func (l *JSONLogger) Level() Level {
+	return l.level
+}
+
+func (l *JSONLogger) Debug(format string, a ...interface{}) {
+	l.write(LevelDebug, "", format, a...)
+}
+
+func (l *JSONLogger) Info(format string, a ...interface{}) {
+	l.write(LevelInfo, "", format, a...)
+}
+
+func (l *JSONLogger) Success(format string, a ...interface{}) {
+	l.write(LevelInfo, "success", format, a...)
 }
 
-func (l *Logger) Error(format string, a ...interface{}) {
-	red := color.New(color.FgHiRed).SprintFunc()
-	fmt.Printf("%s %s\n", red("✗"), fmt.Sprintf(format, a...))
+func (l *JSONLogger) Title(format string, a ...interface{}) {
+	l.write(LevelInfo, "title", format, a...)
 }
 
-func (l *Logger) Info(format string, a ...interface{}) {
-	blue := color.New(color.FgHiBlue).SprintFunc()
-	fmt.Printf("%s %s\n", blue("ℹ"), fmt.Sprintf(format, a...))
+func (l *JSONLogger) Warning(format string, a ...interface{}) {
+	l.write(LevelWarn, "", format, a...)
 }
 
-func (l *Logger) Warning(format string, a ...interface{}) {
-	yellow := color.New(color.FgHiYellow).SprintFunc()
-	fmt.Printf("%s %s\n", yellow("⚠"), fmt.Sprintf(format, a...))
+func (l *JSONLogger) Error(format string, a ...interface{}) {
+	l.write(LevelError, "", format, a...)
 }
 
-func (l *Logger) Title(format string, a ...interface{}) {
-	bold := color.New(color.FgHiGreen, color.Bold).SprintFunc()
-	fmt.Println(bold(fmt.Sprintf(format, a...)))
+func (l *JSONLogger) write(level Level, kind string, format string, a ...interface{}) {
+	if level < l.level {
+		return
+	}
+
+	line, err := json.Marshal(jsonEntry{
+		Time:    l.now().UTC().Format(time.RFC3339Nano),
+		Level:   level.String(),
+		Message: fmt.Sprintf(format, a...),
+		Kind:    kind,
+	})
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level == LevelError {
+		fmt.Fprintln(l.err, string(line))
+		return
+	}
+	fmt.Fprintln(l.out, string(line))
+}
+
+// LoggingCommandRunner logs every command run through runner, with its
+// duration, at debug level.
+type LoggingCommandRunner struct {
+	runner CommandRunner
+	logger Logger
+}
+
+// NewLoggingCommandRunner returns runner logging its commands to logger.
+func NewLoggingCommandRunner(runner CommandRunner, logger Logger) *LoggingCommandRunner {
+	return &LoggingCommandRunner{runner: runner, logger: logger}
+}
+
+func (r *LoggingCommandRunner) Run(name string, args ...string) ([]byte, error) {
+	start := time.Now()
+	output, err := r.runner.Run(name, args...)
+	r.log(start, err, name, args)
+	return output, err
+}
+
+func (r *LoggingCommandRunner) Output(name string, args ...string) ([]byte, error) {
+	start := time.Now()
+	output, err := r.runner.Output(name, args...)
+	r.log(start, err, name, args)
+	return output, err
+}
+
+// RunInteractive attaches the command to the terminal when runner can, or
+// else runs it normally.
+func (r *LoggingCommandRunner) RunInteractive(name string, args ...string) error {
+	start := time.Now()
+
+	var err error
+	if interactive, ok := r.runner.(InteractiveRunner); ok {
+		err = interactive.RunInteractive(name, args...)
+	} else {
+		var output []byte
+		if output, err = r.runner.Run(name, args...); err != nil {
+			err = fmt.Errorf("%v\n%s", err, output)
+		}
+	}
+
+	r.log(start, err, name, args)
+	return err
+}
+
+func (r *LoggingCommandRunner) log(start time.Time, err error, name string, args []string) {
+	if r.logger.Level() > LevelDebug {
+		return
+	}
+
+	command := strings.Join(append([]string{name}, args...), " ")
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		r.logger.Debug("$ %s (%s, failed: %v)", command, duration, err)
+		return
+	}
+	r.logger.Debug("$ %s (%s)", command, duration)
 }
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextLoggerLevelsAndStreams(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := NewTextLogger(&out, &errOut, LevelWarn, false)
+
+	logger.Debug("depuração")
+	logger.Info("informação")
+	logger.Success("sucesso")
+	logger.Warning("aviso %d", 1)
+	logger.Error("erro %d", 2)
+
+	if out.String() != "⚠ aviso 1\n" {
+		t.Errorf("Saída padrão: esperava só o aviso, sem cores, obteve %q", out.String())
+	}
+	if errOut.String() != "✗ erro 2\n" {
+		t.Errorf("Saída de erro: esperava o erro, obteve %q", errOut.String())
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := NewJSONLogger(&out, &errOut, LevelDebug)
+	logger.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	logger.Debug("$ git status")
+	logger.Success("tag %s criada", "v1.2.0")
+	logger.Error("falhou")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Esperava duas linhas na saída padrão, obteve %q", out.String())
+	}
+
+	var entry jsonEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Linha JSON inválida: %v", err)
+	}
+	expected := jsonEntry{Time: "2024-05-01T12:00:00Z", Level: "info", Message: "tag v1.2.0 criada", Kind: "success"}
+	if entry != expected {
+		t.Errorf("Esperava %+v, obteve %+v", expected, entry)
+	}
+
+	if !strings.Contains(errOut.String(), `"level":"error","msg":"falhou"`) {
+		t.Errorf("Esperava o erro na saída de erro, obteve %q", errOut.String())
+	}
+}
+
+// stubRunner responde a qualquer comando com a saída e o erro configurados
+type stubRunner struct {
+	err error
+}
+
+func (s *stubRunner) Run(name string, args ...string) ([]byte, error) {
+	return []byte("saída"), s.err
+}
+
+func (s *stubRunner) Output(name string, args ...string) ([]byte, error) {
+	return []byte("saída"), s.err
+}
+
+func TestLoggingCommandRunner(t *testing.T) {
+	var out bytes.Buffer
+
+	runner := NewLoggingCommandRunner(&stubRunner{}, NewTextLogger(&out, &out, LevelDebug, false))
+	if _, err := runner.Output("git", "rev-parse", "HEAD"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	failing := NewLoggingCommandRunner(&stubRunner{err: fmt.Errorf("exit status 1")}, NewTextLogger(&out, &out, LevelDebug, false))
+	_, _ = failing.Run("git", "push", "origin", "main")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "· $ git rev-parse HEAD (") ||
+		!strings.Contains(lines[1], "$ git push origin main (") || !strings.Contains(lines[1], "failed: exit status 1") {
+		t.Errorf("Log de comandos inesperado: %q", out.String())
+	}
+
+	out.Reset()
+	quiet := NewLoggingCommandRunner(&stubRunner{}, NewTextLogger(&out, &out, LevelInfo, false))
+	_, _ = quiet.Run("git", "status")
+	if out.Len() != 0 {
+		t.Errorf("Acima do nível debug nenhum comando deveria ser registrado, obteve %q", out.String())
+	}
+}
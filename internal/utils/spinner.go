@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -9,18 +10,27 @@ import (
 
 type ProgressSpinner struct {
 	spinner *spinner.Spinner
+	// silent spinners draw nothing, so that quiet and JSON output stay clean.
+	silent bool
 }
 
 func NewProgressSpinner(message string) *ProgressSpinner {
 	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 	s.Suffix = fmt.Sprintf(" %s", message)
+
+	logger := NewLogger()
+	_, text := logger.(*TextLogger)
+
 	return &ProgressSpinner{
 		spinner: s,
+		silent:  !text || logger.Level() > LevelInfo,
 	}
 }
 
 func (p *ProgressSpinner) Start() {
-	p.spinner.Start()
+	if !p.silent {
+		p.spinner.Start()
+	}
 }
 
 func (p *ProgressSpinner) Stop() {
@@ -29,12 +39,16 @@ func (p *ProgressSpinner) Stop() {
 
 func (p *ProgressSpinner) Success(message string) {
 	p.spinner.Stop()
-	fmt.Printf("✓ %s\n", message)
+	if !p.silent {
+		fmt.Printf("✓ %s\n", message)
+	}
 }
 
 func (p *ProgressSpinner) Error(message string) {
 	p.spinner.Stop()
-	fmt.Printf("✗ %s\n", message)
+	if !p.silent {
+		fmt.Fprintf(os.Stderr, "✗ %s\n", message)
+	}
 }
 
 func (p *ProgressSpinner) WithDelay(fn func() error, delay time.Duration) error {
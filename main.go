@@ -3,9 +3,12 @@ package main
 import (
 	"os"
 
+	"github.com/be-tech/version-manager/internal/cli"
 	"github.com/be-tech/version-manager/internal/git"
 	"github.com/be-tech/version-manager/internal/ui"
 	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+	"github.com/be-tech/version-manager/pkg/release"
 	"github.com/joho/godotenv"
 )
 
@@ -14,26 +17,112 @@ func main() {
 	loadEnvFile()
 
 	logger := utils.NewLogger()
+
+	cfg := config.NewConfig()
+	command, err := cli.Parse(os.Args[1:], cfg)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(2)
+	}
+
+	if command.Name == cli.CommandHelp {
+		return
+	}
+
+	// Every logger created from now on, including the command log of the git
+	// commands, follows the flags.
+	configured, err := utils.NewLoggerFor(command.LogFormat, command.LogLevel)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(2)
+	}
+	logger = configured
+	utils.SetDefaultLogger(logger)
+
+	if command.Name == cli.CommandRelease {
+		if err := release.NewReleaseManager(cfg).PublishRelease(command.Args[1]); err != nil {
+			logger.Error("Erro ao publicar a release: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if command.Name == cli.CommandVerify {
+		if !verifyTags(git.NewManager(cfg), command.Args, logger) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.Title("Version Manager - Git version control tool")
 
-	userInterface := ui.NewUI()
+	userInterface := ui.NewUIWithConfig(cfg, command.Provided, command.Interactive)
 
-	config, err := userInterface.CollectUserInput()
+	cfg, err = userInterface.CollectUserInput()
 	if err != nil {
 		logger.Error("Erro ao processar entrada do usuário: %v", err)
 		os.Exit(1)
 	}
 
-	gitManager := git.NewManager(config)
+	gitManager := git.NewManager(cfg)
+	if cfg.DryRun {
+		gitManager = git.NewDryRunManager(cfg)
+	}
+
+	gitManager.SetPreflightConfirm(func(report *git.PreflightReport) (bool, error) {
+		return userInterface.ConfirmContinue(report.String())
+	})
+	gitManager.SetConflictResolver(userInterface.ChooseConflictAction)
 
 	if err := gitManager.ExecuteVersionFlow(); err != nil {
 		logger.Error("Erro ao executar operações Git: %v", err)
 		os.Exit(1)
 	}
 
+	if cfg.DryRun {
+		logger.Success("Simulação concluída: nenhuma alteração foi feita")
+		return
+	}
+
 	logger.Success("Gerenciamento de versão concluído com sucesso!")
 }
 
+// verifyTags checks the signatures of the tags in args, a single tag or a
+// [from, to] range, and reports whether all of them are valid.
+func verifyTags(manager *git.Manager, args []string, logger utils.Logger) bool {
+	var results []git.TagVerification
+	if len(args) == 1 {
+		results = []git.TagVerification{{Tag: args[0], Err: manager.VerifyTag(args[0])}}
+	} else {
+		var err error
+		if results, err = manager.VerifyTags(args[0], args[1]); err != nil {
+			logger.Error("Erro ao listar as tags: %v", err)
+			return false
+		}
+	}
+
+	if len(results) == 0 {
+		logger.Warning("Nenhuma tag de versão no intervalo")
+		return true
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			logger.Error("%s: %v", result.Tag, result.Err)
+			continue
+		}
+		logger.Success("%s: assinatura válida de uma chave permitida", result.Tag)
+	}
+
+	if failed > 0 {
+		logger.Error("%d de %d tag(s) sem assinatura válida de uma chave permitida", failed, len(results))
+		return false
+	}
+	return true
+}
+
 // loadEnvFile tries to load environment variables from .env files
 // searching in multiple locations to ensure it works regardless of where
 // the application is executed from
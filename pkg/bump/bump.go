@@ -0,0 +1,203 @@
+// Package bump writes a release version into the manifests that carry it, so
+// that package.json, Chart.yaml and friends never drift from the git tags.
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Bumper sets the version in one kind of version file.
+type Bumper interface {
+	// Files returns the paths the bumper rewrites, for the release commit.
+	Files() []string
+
+	// Bump writes version, without any tag prefix, into the files.
+	Bump(version string) error
+}
+
+// Built-in version file types.
+const (
+	TypePackageJSON = "package.json"
+	TypeVersion     = "version"
+	TypeChart       = "chart"
+	TypePyproject   = "pyproject"
+	TypeCargo       = "cargo"
+	TypeGo          = "go"
+	TypeRegex       = "regex"
+)
+
+// Types lists the accepted values for Spec.Type.
+var Types = []string{TypePackageJSON, TypeVersion, TypeChart, TypePyproject, TypeCargo, TypeGo, TypeRegex}
+
+// defaultPaths are used when a Spec has no Path.
+var defaultPaths = map[string]string{
+	TypePackageJSON: "package.json",
+	TypeVersion:     "VERSION",
+	TypeChart:       "Chart.yaml",
+	TypePyproject:   "pyproject.toml",
+	TypeCargo:       "Cargo.toml",
+}
+
+// Spec describes a version file as written in the configuration.
+type Spec struct {
+	Type string
+
+	// Path is relative to the repository root, or to the component path in a
+	// monorepo. It may be omitted for types with a conventional file name.
+	Path string
+
+	// Pattern is the regular expression of the "regex" type. Its "version"
+	// named group, or else its first group, is replaced by the version.
+	Pattern string
+
+	// Const is the name of the constant of the "go" type, "Version" by default.
+	Const string
+}
+
+// New returns the bumper for spec, with its path resolved against dir.
+func New(spec Spec, dir string) (Bumper, error) {
+	path := spec.Path
+	if path == "" {
+		path = defaultPaths[spec.Type]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("arquivo de versão do tipo %q precisa de um path", spec.Type)
+	}
+	path = filepath.Join(dir, path)
+
+	switch spec.Type {
+	case TypePackageJSON:
+		return &packageJSON{path: path}, nil
+	case TypeVersion:
+		return &plainFile{path: path}, nil
+	case TypeChart:
+		return &chartYAML{path: path}, nil
+	case TypePyproject:
+		return &tomlFile{path: path, tables: []string{"project", "tool.poetry"}}, nil
+	case TypeCargo:
+		return &tomlFile{path: path, tables: []string{"package", "workspace.package"}}, nil
+	case TypeGo:
+		name := spec.Const
+		if name == "" {
+			name = "Version"
+		}
+		return &regexFile{path: path, pattern: goConstPattern(name)}, nil
+	case TypeRegex:
+		pattern, err := CompilePattern(spec.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &regexFile{path: path, pattern: pattern}, nil
+	default:
+		return nil, fmt.Errorf("tipo de arquivo de versão desconhecido %q", spec.Type)
+	}
+}
+
+// CompilePattern compiles the pattern of a "regex" version file, which must
+// have at least one capturing group for the version.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("o tipo regex precisa de um pattern")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern inválido %q: %v", pattern, err)
+	}
+	if re.NumSubexp() == 0 {
+		return nil, fmt.Errorf("pattern %q precisa de um grupo de captura para a versão", pattern)
+	}
+	return re, nil
+}
+
+// plainFile is a file holding only the version, such as VERSION.
+type plainFile struct {
+	path string
+}
+
+func (f *plainFile) Files() []string {
+	return []string{f.path}
+}
+
+func (f *plainFile) Bump(version string) error {
+	return writeFile(f.path, []byte(version+"\n"))
+}
+
+// regexFile replaces every match of pattern's version group.
+type regexFile struct {
+	path    string
+	pattern *regexp.Regexp
+}
+
+func (f *regexFile) Files() []string {
+	return []string{f.path}
+}
+
+func (f *regexFile) Bump(version string) error {
+	content, err := readFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	updated, n := replaceGroup(content, f.pattern, version)
+	if n == 0 {
+		return fmt.Errorf("%s: nenhuma versão encontrada com o pattern %q", f.path, f.pattern)
+	}
+
+	return writeFile(f.path, updated)
+}
+
+func goConstPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^\s*(?:const\s+)?` + regexp.QuoteMeta(name) + `(?:\s+string)?\s*=\s*"([^"]*)"`)
+}
+
+// replaceGroup replaces, in every match of re, its "version" group or else
+// its first group with version. It returns the new content and the number of
+// replacements.
+func replaceGroup(content []byte, re *regexp.Regexp, version string) ([]byte, int) {
+	group := re.SubexpIndex("version")
+	if group < 0 {
+		group = 1
+	}
+
+	var out []byte
+	last, n := 0, 0
+	for _, match := range re.FindAllSubmatchIndex(content, -1) {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 {
+			continue
+		}
+		out = append(out, content[last:start]...)
+		out = append(out, version...)
+		last = end
+		n++
+	}
+	out = append(out, content[last:]...)
+
+	return out, n
+}
+
+func readFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", path, err)
+	}
+	return content, nil
+}
+
+// writeFile replaces the content of path, keeping its permissions.
+func writeFile(path string, content []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %v", path, err)
+	}
+	return nil
+}
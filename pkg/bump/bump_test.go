@@ -0,0 +1,152 @@
+package bump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("Erro ao escrever arquivo de teste: %v", err)
+	}
+}
+
+func readTestFile(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Erro ao ler arquivo de teste: %v", err)
+	}
+	return string(content)
+}
+
+func TestBumpers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     Spec
+		file     string
+		content  string
+		expected string
+	}{
+		{
+			"VERSION",
+			Spec{Type: TypeVersion},
+			"VERSION", "1.2.3\n",
+			"1.3.0\n",
+		},
+		{
+			"Chart",
+			Spec{Type: TypeChart},
+			"Chart.yaml", "apiVersion: v2\nname: widgets\nversion: 1.2.3\nappVersion: \"1.2.3\"\ndependencies:\n  - name: redis\n    version: 17.0.0\n",
+			"apiVersion: v2\nname: widgets\nversion: 1.3.0\nappVersion: \"1.3.0\"\ndependencies:\n  - name: redis\n    version: 17.0.0\n",
+		},
+		{
+			"Pyproject Poetry",
+			Spec{Type: TypePyproject},
+			"pyproject.toml", "[build-system]\nrequires = [\"poetry-core\"]\n\n[tool.poetry]\nname = \"widgets\"\nversion = \"1.2.3\" # release\n\n[tool.poetry.dependencies]\nversion = \"9.9\"\n",
+			"[build-system]\nrequires = [\"poetry-core\"]\n\n[tool.poetry]\nname = \"widgets\"\nversion = \"1.3.0\" # release\n\n[tool.poetry.dependencies]\nversion = \"9.9\"\n",
+		},
+		{
+			"Cargo",
+			Spec{Type: TypeCargo},
+			"Cargo.toml", "[package]\nname = 'widgets'\nversion = '1.2.3'\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+			"[package]\nname = 'widgets'\nversion = '1.3.0'\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+		},
+		{
+			"Go Const",
+			Spec{Type: TypeGo, Path: "version.go"},
+			"version.go", "package main\n\nconst (\n\tName    = \"widgets\"\n\tVersion = \"1.2.3\"\n)\n",
+			"package main\n\nconst (\n\tName    = \"widgets\"\n\tVersion = \"1.3.0\"\n)\n",
+		},
+		{
+			"Regex",
+			Spec{Type: TypeRegex, Path: "version.h", Pattern: `#define APP_VERSION "(?P<version>[^"]+)"`},
+			"version.h", "#define APP_NAME \"widgets\"\n#define APP_VERSION \"1.2.3\"\n",
+			"#define APP_NAME \"widgets\"\n#define APP_VERSION \"1.3.0\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, dir, tc.file, tc.content)
+
+			bumper, err := New(tc.spec, dir)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			if err := bumper.Bump("1.3.0"); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			if content := readTestFile(t, dir, tc.file); content != tc.expected {
+				t.Errorf("Conteúdo inesperado:\n%s\nesperava:\n%s", content, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPackageJSONWithLock(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "package.json", "{\n  \"name\": \"widgets\",\n  \"engines\": { \"version\": \"x\" },\n  \"version\": \"1.2.3\",\n  \"dependencies\": {}\n}\n")
+	writeTestFile(t, dir, "package-lock.json", "{\n  \"name\": \"widgets\",\n  \"version\": \"1.2.3\",\n  \"lockfileVersion\": 3,\n  \"packages\": {\n    \"\": {\n      \"name\": \"widgets\",\n      \"version\": \"1.2.3\"\n    },\n    \"node_modules/left-pad\": {\n      \"version\": \"1.2.3\"\n    }\n  }\n}\n")
+
+	bumper, err := New(Spec{Type: TypePackageJSON}, dir)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if files := bumper.Files(); len(files) != 2 {
+		t.Errorf("Esperava package.json e package-lock.json, obteve %v", files)
+	}
+
+	if err := bumper.Bump("2.0.0-rc.1"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expectedPackage := "{\n  \"name\": \"widgets\",\n  \"engines\": { \"version\": \"x\" },\n  \"version\": \"2.0.0-rc.1\",\n  \"dependencies\": {}\n}\n"
+	if content := readTestFile(t, dir, "package.json"); content != expectedPackage {
+		t.Errorf("package.json inesperado:\n%s", content)
+	}
+
+	expectedLock := "{\n  \"name\": \"widgets\",\n  \"version\": \"2.0.0-rc.1\",\n  \"lockfileVersion\": 3,\n  \"packages\": {\n    \"\": {\n      \"name\": \"widgets\",\n      \"version\": \"2.0.0-rc.1\"\n    },\n    \"node_modules/left-pad\": {\n      \"version\": \"1.2.3\"\n    }\n  }\n}\n"
+	if content := readTestFile(t, dir, "package-lock.json"); content != expectedLock {
+		t.Errorf("package-lock.json inesperado:\n%s", content)
+	}
+}
+
+func TestBumpErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "pyproject.toml", "[project]\nname = \"widgets\"\ndynamic = [\"version\"]\n")
+	writeTestFile(t, dir, "version.h", "#define APP_NAME \"widgets\"\n")
+
+	testCases := []struct {
+		name string
+		spec Spec
+	}{
+		{"Missing File", Spec{Type: TypeVersion, Path: "missing/VERSION"}},
+		{"Dynamic Version", Spec{Type: TypePyproject}},
+		{"Pattern Without Match", Spec{Type: TypeRegex, Path: "version.h", Pattern: `APP_VERSION "(.*)"`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bumper, err := New(tc.spec, dir)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if err := bumper.Bump("1.3.0"); err == nil {
+				t.Error("Esperava erro, obteve nil")
+			}
+		})
+	}
+
+	if _, err := New(Spec{Type: TypeRegex, Path: "version.h", Pattern: "APP_VERSION"}, dir); err == nil {
+		t.Error("Pattern sem grupo de captura deveria ser rejeitado")
+	}
+}
@@ -0,0 +1,243 @@
+package bump
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// packageJSON updates package.json and, when present next to it,
+// package-lock.json, as `npm version --no-git-tag-version` does. The files are
+// edited in place so that their formatting is kept.
+type packageJSON struct {
+	path string
+}
+
+func (p *packageJSON) lockPath() string {
+	return filepath.Join(filepath.Dir(p.path), "package-lock.json")
+}
+
+func (p *packageJSON) Files() []string {
+	files := []string{p.path}
+	if _, err := os.Stat(p.lockPath()); err == nil {
+		files = append(files, p.lockPath())
+	}
+	return files
+}
+
+func (p *packageJSON) Bump(version string) error {
+	if err := setJSONString(p.path, version, true, "version"); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(p.lockPath()); err != nil {
+		return nil
+	}
+
+	if err := setJSONString(p.lockPath(), version, true, "version"); err != nil {
+		return err
+	}
+	// lockfileVersion 2 and 3 repeat the root package under packages[""].
+	return setJSONString(p.lockPath(), version, false, "packages", "", "version")
+}
+
+var errJSONPathNotFound = errors.New("caminho não encontrado")
+
+// setJSONString replaces the string at keyPath in the JSON file at path.
+func setJSONString(path string, value string, required bool, keyPath ...string) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := findJSONString(content, keyPath)
+	if errors.Is(err, errJSONPathNotFound) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: campo %q: %v", path, strings.Join(keyPath, "."), err)
+	}
+
+	encoded, _ := json.Marshal(value)
+
+	var updated bytes.Buffer
+	updated.Write(content[:start])
+	updated.Write(encoded)
+	updated.Write(content[end:])
+
+	return writeFile(path, updated.Bytes())
+}
+
+// findJSONString returns the byte range, quotes included, of the string value
+// found by following keyPath through nested objects.
+func findJSONString(content []byte, keyPath []string) (int, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	for depth := 0; ; depth++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return 0, 0, errJSONPathNotFound
+		}
+
+		found := false
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return 0, 0, err
+			}
+
+			if keyTok.(string) != keyPath[depth] {
+				if err := skipJSONValue(dec); err != nil {
+					return 0, 0, err
+				}
+				continue
+			}
+
+			if depth < len(keyPath)-1 {
+				found = true
+				break
+			}
+
+			afterKey := int(dec.InputOffset())
+			valueTok, err := dec.Token()
+			if err != nil {
+				return 0, 0, err
+			}
+			if _, ok := valueTok.(string); !ok {
+				return 0, 0, errors.New("o valor não é um texto")
+			}
+
+			end := int(dec.InputOffset())
+			start := afterKey + bytes.IndexByte(content[afterKey:end], '"')
+			return start, end, nil
+		}
+
+		if !found {
+			return 0, 0, errJSONPathNotFound
+		}
+	}
+}
+
+func skipJSONValue(dec *json.Decoder) error {
+	open := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				open++
+			default:
+				open--
+			}
+		}
+
+		if open == 0 {
+			return nil
+		}
+	}
+}
+
+var (
+	chartVersionPattern    = regexp.MustCompile(`(?m)^version:[ \t]*["']?([^"'\s#]+)["']?`)
+	chartAppVersionPattern = regexp.MustCompile(`(?m)^appVersion:[ \t]*["']?([^"'\s#]+)["']?`)
+)
+
+// chartYAML updates the version of a Helm chart and, when the chart declares
+// one, its appVersion.
+type chartYAML struct {
+	path string
+}
+
+func (c *chartYAML) Files() []string {
+	return []string{c.path}
+}
+
+func (c *chartYAML) Bump(version string) error {
+	content, err := readFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	updated, n := replaceGroup(content, chartVersionPattern, version)
+	if n == 0 {
+		return fmt.Errorf("%s: campo \"version\" não encontrado", c.path)
+	}
+	updated, _ = replaceGroup(updated, chartAppVersionPattern, version)
+
+	return writeFile(c.path, updated)
+}
+
+var (
+	tomlTablePattern   = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(?:#.*)?$`)
+	tomlVersionPattern = regexp.MustCompile(`^(\s*version\s*=\s*)(["'])[^"']*(["'])`)
+)
+
+// tomlFile updates the version key of the first of tables present in a TOML
+// manifest, such as [project] in pyproject.toml or [package] in Cargo.toml.
+type tomlFile struct {
+	path   string
+	tables []string
+}
+
+func (t *tomlFile) Files() []string {
+	return []string{t.path}
+}
+
+func (t *tomlFile) Bump(version string) error {
+	content, err := readFile(t.path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+
+	table := ""
+	for i, line := range lines {
+		if match := tomlTablePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n")); match != nil {
+			table = strings.TrimSpace(match[1])
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "[[") {
+			table = ""
+			continue
+		}
+
+		if !contains(t.tables, table) {
+			continue
+		}
+
+		if match := tomlVersionPattern.FindStringSubmatchIndex(line); match != nil {
+			prefix := line[:match[3]]
+			quote := line[match[4]:match[5]]
+			lines[i] = prefix + quote + version + quote + line[match[1]:]
+			return writeFile(t.path, []byte(strings.Join(lines, "")))
+		}
+	}
+
+	return fmt.Errorf("%s: campo \"version\" não encontrado em [%s]", t.path, strings.Join(t.tables, "] ou ["))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+const (
+	GroupByType  = "type"
+	GroupByScope = "scope"
+)
+
+// GroupModes lists the accepted values for Options.GroupBy.
+var GroupModes = []string{GroupByType, GroupByScope}
+
+// Options controls how a changelog section is rendered.
+type Options struct {
+	Version     string
+	PreviousTag string
+	Date        time.Time
+	GroupBy     string
+	Links       *Links
+}
+
+// typeTitles orders and names the sections used when grouping by type.
+var typeTitles = []struct {
+	commitType string
+	title      string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+}
+
+const (
+	breakingTitle = "⚠ BREAKING CHANGES"
+	otherTitle    = "Other Changes"
+)
+
+var issueReference = regexp.MustCompile(`(^|[^\w&/])#(\d+)\b`)
+
+type entry struct {
+	commit version.Commit
+	cc     version.ConventionalCommit
+	text   string
+}
+
+// Generate renders the commits between Options.PreviousTag and
+// Options.Version as a Markdown section. Commits that are not Conventional
+// Commits are listed under "Other Changes"; chore, ci, test, build and style
+// commits are left out.
+func Generate(commits []version.Commit, opts Options) string {
+	var entries []entry
+	for _, commit := range commits {
+		cc, ok := version.ParseConventional(commit)
+		text := commit.Subject
+		if ok {
+			if skipType(cc.Type) && !cc.Breaking {
+				continue
+			}
+			text = strings.TrimSpace(commit.Subject[strings.Index(commit.Subject, ":")+1:])
+		} else if strings.HasPrefix(commit.Subject, "Merge ") {
+			continue
+		}
+		entries = append(entries, entry{commit: commit, cc: cc, text: text})
+	}
+
+	var b strings.Builder
+	b.WriteString(heading(opts))
+
+	if len(entries) == 0 {
+		b.WriteString("\nNo notable changes.\n")
+		return b.String()
+	}
+
+	var breaking []entry
+	for _, e := range entries {
+		if e.cc.Breaking {
+			breaking = append(breaking, e)
+		}
+	}
+	writeSection(&b, breakingTitle, breaking, opts, true)
+
+	if opts.GroupBy == GroupByScope {
+		writeByScope(&b, entries, opts)
+	} else {
+		writeByType(&b, entries, opts)
+	}
+
+	return b.String()
+}
+
+func skipType(commitType string) bool {
+	switch commitType {
+	case "chore", "ci", "test", "build", "style":
+		return true
+	}
+	return false
+}
+
+func heading(opts Options) string {
+	title := opts.Version
+	if opts.Links != nil && opts.PreviousTag != "" {
+		if url := opts.Links.CompareURL(opts.PreviousTag, opts.Version); url != "" {
+			title = fmt.Sprintf("[%s](%s)", opts.Version, url)
+		}
+	}
+
+	if opts.Date.IsZero() {
+		return fmt.Sprintf("## %s\n", title)
+	}
+	return fmt.Sprintf("## %s (%s)\n", title, opts.Date.Format("2006-01-02"))
+}
+
+func writeByType(b *strings.Builder, entries []entry, opts Options) {
+	used := make(map[string]bool)
+
+	for _, section := range typeTitles {
+		var group []entry
+		for _, e := range entries {
+			if e.cc.Type == section.commitType {
+				group = append(group, e)
+			}
+		}
+		writeSection(b, section.title, group, opts, true)
+		used[section.commitType] = true
+	}
+
+	var others []entry
+	for _, e := range entries {
+		if !used[e.cc.Type] {
+			others = append(others, e)
+		}
+	}
+	writeSection(b, otherTitle, others, opts, true)
+}
+
+func writeByScope(b *strings.Builder, entries []entry, opts Options) {
+	groups := make(map[string][]entry)
+	var scopes []string
+
+	for _, e := range entries {
+		scope := e.cc.Scope
+		if _, ok := groups[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		groups[scope] = append(groups[scope], e)
+	}
+
+	sort.Slice(scopes, func(i, j int) bool {
+		// Changes without a scope go last.
+		if scopes[i] == "" || scopes[j] == "" {
+			return scopes[j] == ""
+		}
+		return scopes[i] < scopes[j]
+	})
+
+	for _, scope := range scopes {
+		title := scope
+		if title == "" {
+			title = otherTitle
+		}
+		writeSection(b, title, groups[scope], opts, false)
+	}
+}
+
+func writeSection(b *strings.Builder, title string, entries []entry, opts Options, showScope bool) {
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, e := range entries {
+		b.WriteString("* ")
+		if showScope && e.cc.Scope != "" {
+			fmt.Fprintf(b, "**%s:** ", e.cc.Scope)
+		}
+		b.WriteString(linkIssues(e.text, opts.Links))
+		b.WriteString(" (" + linkCommit(e.commit.Hash, opts.Links) + ")\n")
+	}
+}
+
+func linkCommit(hash string, links *Links) string {
+	short := hash
+	if len(short) > 7 {
+		short = short[:7]
+	}
+
+	if links == nil {
+		return short
+	}
+	return fmt.Sprintf("[%s](%s)", short, links.CommitURL(hash))
+}
+
+func linkIssues(text string, links *Links) string {
+	if links == nil {
+		return text
+	}
+
+	return issueReference.ReplaceAllStringFunc(text, func(match string) string {
+		parts := issueReference.FindStringSubmatch(match)
+		return fmt.Sprintf("%s[#%s](%s)", parts[1], parts[2], links.IssueURL(parts[2]))
+	})
+}
+
+// Prepend inserts section at the top of the changelog file at path, below its
+// title if it has one, creating the file when it does not exist.
+func Prepend(path string, section string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("erro ao ler %s: %v", path, err)
+	}
+
+	existing := string(content)
+	header := "# Changelog\n\n"
+
+	if strings.HasPrefix(existing, "# ") {
+		end := strings.Index(existing, "\n")
+		if end == -1 {
+			end = len(existing)
+		}
+		header = strings.TrimRight(existing[:end], "\r") + "\n\n"
+		existing = strings.TrimLeft(existing[end:], "\r\n")
+	}
+
+	updated := header + strings.TrimRight(section, "\n") + "\n"
+	if existing != "" {
+		updated += "\n" + existing
+	}
+
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %v", path, err)
+	}
+	return nil
+}
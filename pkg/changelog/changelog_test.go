@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/remote"
+	"github.com/be-tech/version-manager/pkg/version"
+)
+
+var testCommits = []version.Commit{
+	{Hash: "1111111aaaa", Subject: "feat(api): add search endpoint (#12)"},
+	{Hash: "2222222bbbb", Subject: "fix: handle empty tags"},
+	{Hash: "3333333cccc", Subject: "chore: bump deps"},
+	{Hash: "4444444dddd", Subject: "refactor(api)!: drop v1 routes"},
+	{Hash: "5555555eeee", Subject: "update readme"},
+}
+
+func TestGenerateByType(t *testing.T) {
+	repo, _ := remote.Parse("git@github.com:acme/widgets.git")
+
+	notes := Generate(testCommits, Options{
+		Version:     "v1.3.0",
+		PreviousTag: "v1.2.0",
+		Date:        time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		Links:       NewLinks(repo, ""),
+	})
+
+	expected := []string{
+		"## [v1.3.0](https://github.com/acme/widgets/compare/v1.2.0...v1.3.0) (2026-10-17)",
+		"### ⚠ BREAKING CHANGES\n\n* **api:** drop v1 routes ([4444444](https://github.com/acme/widgets/commit/4444444dddd))",
+		"### Features\n\n* **api:** add search endpoint ([#12](https://github.com/acme/widgets/issues/12))",
+		"### Bug Fixes\n\n* handle empty tags ([2222222](https://github.com/acme/widgets/commit/2222222bbbb))",
+		"### Other Changes\n\n* update readme",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(notes, e) {
+			t.Errorf("Notas deveriam conter:\n%s\n\nobteve:\n%s", e, notes)
+		}
+	}
+
+	if strings.Contains(notes, "bump deps") {
+		t.Errorf("Commits chore não devem aparecer:\n%s", notes)
+	}
+}
+
+func TestGenerateByScopeWithGitLabLinks(t *testing.T) {
+	repo, _ := remote.Parse("https://gitlab.example.com/group/sub/widgets.git")
+
+	notes := Generate(testCommits, Options{
+		Version: "v1.3.0",
+		GroupBy: GroupByScope,
+		Links:   NewLinks(repo, "gitlab"),
+	})
+
+	apiSection := strings.Index(notes, "### api")
+	otherSection := strings.Index(notes, "### Other Changes")
+	if apiSection == -1 || otherSection == -1 || apiSection > otherSection {
+		t.Fatalf("Esperava a seção 'api' antes de 'Other Changes':\n%s", notes)
+	}
+
+	if !strings.Contains(notes, "https://gitlab.example.com/group/sub/widgets/-/commit/2222222bbbb") {
+		t.Errorf("Links do GitLab incorretos:\n%s", notes)
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+
+	if err := Prepend(path, "## v1.0.0\n\n* first\n"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if err := Prepend(path, "## v1.1.0\n\n* second\n"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	expected := "# Changelog\n\n## v1.1.0\n\n* second\n\n## v1.0.0\n\n* first\n"
+	if string(content) != expected {
+		t.Errorf("Conteúdo inesperado:\n%q\nesperava:\n%q", content, expected)
+	}
+}
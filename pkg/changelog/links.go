@@ -0,0 +1,61 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/be-tech/version-manager/pkg/remote"
+)
+
+// Links builds web links to commits, issues and tag comparisons on the
+// repository's forge.
+type Links struct {
+	base   string
+	gitlab bool
+}
+
+// NewLinks returns the links for a repository hosted at repo. provider is
+// "github", "gitlab", "gitea" or "forgejo"; when empty it is guessed from the
+// host name, and nil is returned if it cannot be.
+func NewLinks(repo *remote.URL, provider string) *Links {
+	if repo == nil {
+		return nil
+	}
+
+	if provider == "" {
+		switch {
+		case strings.Contains(repo.Host, "gitlab"):
+			provider = "gitlab"
+		case strings.Contains(repo.Host, "github"):
+			provider = "github"
+		}
+	}
+
+	switch provider {
+	case "github", "gitea", "forgejo":
+		return &Links{base: repo.WebURL()}
+	case "gitlab":
+		return &Links{base: repo.WebURL(), gitlab: true}
+	default:
+		return nil
+	}
+}
+
+func (l *Links) section() string {
+	if l.gitlab {
+		return l.base + "/-"
+	}
+	return l.base
+}
+
+func (l *Links) CommitURL(hash string) string {
+	return fmt.Sprintf("%s/commit/%s", l.section(), hash)
+}
+
+func (l *Links) IssueURL(number string) string {
+	return fmt.Sprintf("%s/issues/%s", l.section(), number)
+}
+
+func (l *Links) CompareURL(from string, to string) string {
+	return fmt.Sprintf("%s/compare/%s...%s", l.section(), from, to)
+}
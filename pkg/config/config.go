@@ -1,5 +1,14 @@
 package config
 
+import (
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/bump"
+)
+
 type Config struct {
 	Remote string
 
@@ -11,6 +20,9 @@ type Config struct {
 
 	RemoveBranch bool
 
+	// DeleteRemoteBranch also deletes the removed source branch on Remote.
+	DeleteRemoteBranch bool
+
 	Tag string
 
 	CreateRelease bool
@@ -20,11 +32,402 @@ type Config struct {
 	ReleaseNotes string
 
 	RepoType string
+
+	// ReleaseExisting is what to do when the tag already has a release, one
+	// of ExistingReleasePolicies.
+	ReleaseExisting string
+
+	// ReleaseDraft creates the release as a draft, to be published later with
+	// "release publish".
+	ReleaseDraft bool
+
+	// ReleaseMakeLatest is GitHub's make_latest: "true", "false" or "legacy".
+	ReleaseMakeLatest string
+
+	// ReleasedAt is GitLab's released_at. The zero time uses the current time.
+	ReleasedAt time.Time
+
+	// ReleaseAssets are glob patterns of the files attached to the release,
+	// along with a generated SHA256SUMS file.
+	ReleaseAssets []string
+
+	// Hosts map self-hosted forges to their provider and API base URL.
+	Hosts []Host
+
+	// ProtectedBranches are never offered for removal after a merge.
+	ProtectedBranches []string
+
+	// BumpRules map destination branch patterns to the bump type proposed by default.
+	BumpRules []BumpRule
+
+	// Channels map destination branch patterns to a prerelease channel.
+	Channels []Channel
+
+	// Components, when set, turn the repository into a monorepo: each component
+	// is versioned and tagged on its own instead of the whole repository.
+	Components []Component
+
+	// VersionFiles get the new version written and committed in the release
+	// commit, before the tag is created.
+	VersionFiles []bump.Spec
+
+	// ChangelogFile, when set, gets the generated release notes prepended and
+	// committed as part of the release commit.
+	ChangelogFile string
+
+	// ChangelogGroupBy groups the generated notes by commit "type" or "scope".
+	ChangelogGroupBy string
+
+	// DryRun prints the git commands and API requests instead of running them.
+	DryRun bool
+
+	// Signing configures signed release tags and their verification.
+	Signing Signing
+
+	// Merge selects how the source branch is merged into the destination.
+	Merge Merge
+
+	// PullRequest replaces the local merge with a pull or merge request.
+	PullRequest PullRequest
+
+	// Policy restricts what the flow may do, checked before it starts.
+	Policy Policy
+
+	// TagPrefix is prepended to every generated version, e.g. "v" or "release-".
+	TagPrefix string
+}
+
+// Policies for a tag that already has a release.
+const (
+	ExistingFail   = "fail"
+	ExistingUpdate = "update"
+	ExistingSkip   = "skip"
+)
+
+// ExistingReleasePolicies lists the accepted values of ReleaseExisting.
+var ExistingReleasePolicies = []string{ExistingFail, ExistingUpdate, ExistingSkip}
+
+// MakeLatestValues lists the accepted values of ReleaseMakeLatest.
+var MakeLatestValues = []string{"true", "false", "legacy"}
+
+// BumpRule proposes Bump when the destination branch matches Branch, a
+// path.Match pattern such as "main" or "hotfix/*".
+type BumpRule struct {
+	Branch string
+	Bump   string
+}
+
+// Channel publishes prereleases named after Identifier, e.g. "alpha" or "rc",
+// when the destination branch matches Branch, a path.Match pattern.
+type Channel struct {
+	Branch     string
+	Identifier string
+}
+
+// Signing formats, as in git's gpg.format.
+const (
+	SigningGPG = "gpg"
+	SigningSSH = "ssh"
+)
+
+// SigningFormats lists the accepted values of Signing.Format.
+var SigningFormats = []string{SigningGPG, SigningSSH}
+
+// Signing makes release tags signed with Key in Format. Empty values fall
+// back to git's user.signingkey and gpg.format. AllowedSigners, an SSH
+// allowed signers file, and AllowedKeys, GPG fingerprints or key IDs, list
+// the keys accepted when verifying tags.
+type Signing struct {
+	Tags           bool
+	Format         string
+	Key            string
+	AllowedSigners string
+	AllowedKeys    []string
+}
+
+// Host declares the provider, one of ReleaseProviders, of a forge such as a
+// GitHub Enterprise, self-managed GitLab or Gitea instance. APIURL overrides the
+// provider's default API base URL on that host.
+type Host struct {
+	Name     string
+	Provider string
+	APIURL   string
+}
+
+// Component is a monorepo module or service living under Path, tagged as
+// TagPrefix followed by its version, e.g. "services/billing/v1.4.0".
+type Component struct {
+	Name      string
+	Path      string
+	TagPrefix string
+
+	// VersionFiles are relative to Path.
+	VersionFiles []bump.Spec
+}
+
+// Merge strategies.
+const (
+	// MergeDefault runs a plain "git merge", fast-forwarding when possible.
+	MergeDefault = "merge"
+	// MergeFastForwardOnly fails unless the destination can be fast-forwarded.
+	MergeFastForwardOnly = "ff-only"
+	// MergeNoFastForward always creates a merge commit.
+	MergeNoFastForward = "no-ff"
+	// MergeSquash commits all the changes of the source as a single commit.
+	MergeSquash = "squash"
+	// MergeRebase rebases the source onto the destination, then fast-forwards.
+	MergeRebase = "rebase"
+)
+
+// MergeStrategies lists the accepted merge strategies.
+var MergeStrategies = []string{MergeDefault, MergeFastForwardOnly, MergeNoFastForward, MergeSquash, MergeRebase}
+
+// What to do when the merge stops on conflicts.
+const (
+	// ConflictAsk asks the user, or aborts when nobody can be asked.
+	ConflictAsk = "ask"
+	// ConflictAbort aborts the merge and rolls the run back.
+	ConflictAbort = "abort"
+	// ConflictMergetool runs git mergetool and continues once resolved.
+	ConflictMergetool = "mergetool"
+	// ConflictStop stops the run and leaves the merge in progress.
+	ConflictStop = "stop"
+)
+
+// ConflictActions lists the accepted values of Merge.OnConflict.
+var ConflictActions = []string{ConflictAsk, ConflictAbort, ConflictMergetool, ConflictStop}
+
+// DefaultIssuePattern matches Jira-style issue keys, e.g. "PROJ-123".
+const DefaultIssuePattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// Merge is the strategy and commit message template of the merge into the
+// destination branch. Rules override both for the destinations they match.
+// Message is a text/template, see MergeMessageData; IssuePattern is the
+// regular expression finding issue keys in the branch name and the commits.
+// OnConflict is one of ConflictActions, ConflictAsk when empty.
+type Merge struct {
+	Strategy     string
+	Message      string
+	IssuePattern string
+	OnConflict   string
+	Rules        []MergeRule
+}
+
+// MergeRule sets the strategy or message, when not empty, of merges into the
+// destination branches matching Branch, a path.Match pattern.
+type MergeRule struct {
+	Branch   string
+	Strategy string
+	Message  string
+}
+
+// PullRequest makes the flow push the source branch and merge it through a
+// GitHub pull request or GitLab merge request, for protected destinations.
+// With Wait, the run waits up to Timeout for it to be merged and then tags
+// the merged commit; otherwise it ends once the pull request is open.
+type PullRequest struct {
+	Enabled   bool
+	AutoMerge bool
+	Wait      bool
+	Timeout   time.Duration
+}
+
+// Policy holds the promotion rules of a repository. A run that breaks one is
+// blocked, unless Override is set, in which case each violation is logged.
+type Policy struct {
+	// Promotions restrict which sources may be merged into a destination.
+	Promotions []PromotionRule
+	// Bumps restrict the bump types allowed on a destination.
+	Bumps []BumpPolicy
+	// NeverDelete are path.Match patterns of branches that must never be
+	// removed, on top of ProtectedBranches.
+	NeverDelete []string
+	Override    bool
+}
+
+// PromotionRule allows only the sources matching From to be merged into the
+// destinations matching To. Both are path.Match patterns.
+type PromotionRule struct {
+	To   string
+	From []string
+}
+
+// BumpPolicy allows only the bump types in Allow on the destinations matching
+// Branch, a path.Match pattern.
+type BumpPolicy struct {
+	Branch string
+	Allow  []string
+}
+
+// PromotionSources returns the sources allowed into destination by the first
+// matching promotion rule, and false when no rule restricts it.
+func (c *Config) PromotionSources(destination string) ([]string, bool) {
+	for _, rule := range c.Policy.Promotions {
+		if matched, _ := path.Match(rule.To, destination); matched {
+			return rule.From, true
+		}
+	}
+	return nil, false
+}
+
+// AllowedBumps returns the bump types allowed on destination by the first
+// matching bump policy, and false when no policy restricts it.
+func (c *Config) AllowedBumps(destination string) ([]string, bool) {
+	for _, rule := range c.Policy.Bumps {
+		if matched, _ := path.Match(rule.Branch, destination); matched {
+			return rule.Allow, true
+		}
+	}
+	return nil, false
+}
+
+// MergeMessageData is what a merge message template can refer to.
+type MergeMessageData struct {
+	Source      string
+	Destination string
+	// Version is the tag about to be created, empty when none is.
+	Version string
+	// Issues are the issue keys referenced by the source branch.
+	Issues []string
+}
+
+// ParseMergeMessage parses a merge message template. Besides the text/template
+// builtins it has join, as in strings.Join.
+func ParseMergeMessage(text string) (*template.Template, error) {
+	return template.New("merge").Option("missingkey=error").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+}
+
+// MergeStrategyFor returns the merge strategy into branch.
+func (c *Config) MergeStrategyFor(branch string) string {
+	for _, rule := range c.Merge.Rules {
+		if matched, _ := path.Match(rule.Branch, branch); matched && rule.Strategy != "" {
+			return rule.Strategy
+		}
+	}
+	if c.Merge.Strategy != "" {
+		return c.Merge.Strategy
+	}
+	return MergeDefault
+}
+
+// MergeMessageFor returns the merge message template into branch, or "" to
+// keep git's message.
+func (c *Config) MergeMessageFor(branch string) string {
+	for _, rule := range c.Merge.Rules {
+		if matched, _ := path.Match(rule.Branch, branch); matched && rule.Message != "" {
+			return rule.Message
+		}
+	}
+	return c.Merge.Message
 }
 
 func NewConfig() *Config {
 	return &Config{
-		Push:         false,
-		RemoveBranch: false,
+		Push:              false,
+		RemoveBranch:      false,
+		ProtectedBranches: []string{"master", "main", "develop", "stage"},
+		Channels:          []Channel{{Branch: "stage", Identifier: "pre"}},
+		ChangelogGroupBy:  "type",
+		ReleaseExisting:   ExistingFail,
+		PullRequest:       PullRequest{Wait: true, Timeout: 30 * time.Minute},
+	}
+}
+
+// IsProtected reports whether branch is in ProtectedBranches or matches a
+// NeverDelete pattern of the policy.
+func (c *Config) IsProtected(branch string) bool {
+	for _, protected := range c.ProtectedBranches {
+		if protected == branch {
+			return true
+		}
+	}
+	for _, pattern := range c.Policy.NeverDelete {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpFor returns the bump type of the first rule matching branch, or "" when none does.
+func (c *Config) BumpFor(branch string) string {
+	for _, rule := range c.BumpRules {
+		if matched, _ := path.Match(rule.Branch, branch); matched {
+			return rule.Bump
+		}
+	}
+	return ""
+}
+
+// ChannelFor returns the prerelease identifier of the first channel matching
+// branch, or "" when branch publishes stable releases.
+func (c *Config) ChannelFor(branch string) string {
+	for _, channel := range c.Channels {
+		if matched, _ := path.Match(channel.Branch, branch); matched {
+			return channel.Identifier
+		}
+	}
+	return ""
+}
+
+// ProviderFor returns the release provider of the forge at host: the one
+// configured in Hosts, or else one guessed from the host name. It returns ""
+// when the provider cannot be determined.
+func (c *Config) ProviderFor(host string) string {
+	if h := c.hostConfig(host); h != nil {
+		return h.Provider
+	}
+
+	switch host = strings.ToLower(host); {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return "github"
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return "gitlab"
+	case host == "gitea.com" || strings.HasPrefix(host, "gitea."):
+		return "gitea"
+	case host == "codeberg.org" || strings.HasPrefix(host, "forgejo."):
+		return "forgejo"
+	case host == "bitbucket.org":
+		return "bitbucket"
+	case strings.HasPrefix(host, "bitbucket."):
+		return "bitbucket-server"
+	}
+	return ""
+}
+
+// APIURLFor returns the API base URL of provider at host, without a trailing
+// slash: the one configured in Hosts, or else the provider's default for
+// the public forges and self-hosted instances.
+func (c *Config) APIURLFor(host string, provider string) string {
+	if h := c.hostConfig(host); h != nil && h.APIURL != "" {
+		return strings.TrimSuffix(h.APIURL, "/")
+	}
+
+	host = strings.ToLower(host)
+
+	switch provider {
+	case "github":
+		if host == "github.com" {
+			return "https://api.github.com"
+		}
+		return "https://" + host + "/api/v3"
+	case "gitlab":
+		return "https://" + host + "/api/v4"
+	case "gitea", "forgejo":
+		return "https://" + host + "/api/v1"
+	case "bitbucket":
+		return "https://api.bitbucket.org/2.0"
+	case "bitbucket-server":
+		return "https://" + host + "/rest/api/1.0"
+	}
+	return ""
+}
+
+func (c *Config) hostConfig(host string) *Host {
+	for i := range c.Hosts {
+		if strings.EqualFold(c.Hosts[i].Name, host) {
+			return &c.Hosts[i]
+		}
 	}
+	return nil
 }
@@ -0,0 +1,914 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/bump"
+	"github.com/be-tech/version-manager/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoFileName is the name of the per-repository configuration file,
+// committed at the root of the repository.
+const RepoFileName = ".versionmanager.yml"
+
+// ReleaseProviders lists the accepted values for the release provider.
+var ReleaseProviders = []string{"github", "gitlab", "gitea", "forgejo", "bitbucket", "bitbucket-server"}
+
+// Keys reported by LoadFile and LoadEnv for the values they set.
+const (
+	KeyRemote            = "remote"
+	KeyProtectedBranches = "protected_branches"
+	KeyBumpRules         = "bump_rules"
+	KeyReleaseProvider   = "release_provider"
+	KeyReleaseAssets     = "release_assets"
+	KeyReleaseExisting   = "release_on_existing"
+	KeyReleaseDraft      = "release_draft"
+	KeyReleaseMakeLatest = "release_make_latest"
+	KeyTagPrefix         = "tag_prefix"
+	KeyChangelog         = "changelog"
+	KeyChannels          = "channels"
+	KeyComponents        = "components"
+	KeyVersionFiles      = "version_files"
+	KeyHosts             = "hosts"
+	KeySigning           = "signing"
+	KeyMerge             = "merge"
+	KeyPullRequest       = "pull_request"
+	KeyPolicy            = "policy"
+	KeyDeleteRemote      = "delete_remote_branch"
+)
+
+type fileConfig struct {
+	Remote            *nonEmptyString  `yaml:"remote"`
+	ProtectedBranches *[]branchName    `yaml:"protected_branches"`
+	BumpRules         *[]fileBumpRule  `yaml:"bump_rules"`
+	Release           *fileRelease     `yaml:"release"`
+	TagPrefix         *tagPrefix       `yaml:"tag_prefix"`
+	Changelog         *fileChangelog   `yaml:"changelog"`
+	Channels          *[]fileChannel   `yaml:"channels"`
+	Components        *[]fileComponent `yaml:"components"`
+	VersionFiles      *[]versionFile   `yaml:"version_files"`
+	Hosts             *[]fileHost      `yaml:"hosts"`
+	Signing           *fileSigning     `yaml:"signing"`
+	Merge             *fileMerge       `yaml:"merge"`
+	PullRequest       *filePullRequest `yaml:"pull_request"`
+	Policy            *filePolicy      `yaml:"policy"`
+	DeleteRemote      *bool            `yaml:"delete_remote_branch"`
+}
+
+type filePolicy struct {
+	Promotions  *[]filePromotionRule `yaml:"promotions"`
+	Bumps       *[]fileBumpPolicy    `yaml:"bumps"`
+	NeverDelete *[]branchPattern     `yaml:"never_delete"`
+}
+
+type filePromotionRule struct {
+	To   branchPattern   `yaml:"to"`
+	From []branchPattern `yaml:"from"`
+}
+
+type fileBumpPolicy struct {
+	Branch branchPattern `yaml:"branch"`
+	Allow  []bumpType    `yaml:"allow"`
+}
+
+type filePullRequest struct {
+	Enabled   *bool             `yaml:"enabled"`
+	AutoMerge *bool             `yaml:"auto_merge"`
+	Wait      *bool             `yaml:"wait"`
+	Timeout   *positiveDuration `yaml:"timeout"`
+}
+
+type fileMerge struct {
+	Strategy     *mergeStrategy   `yaml:"strategy"`
+	Message      *mergeMessage    `yaml:"message"`
+	IssuePattern *issuePattern    `yaml:"issue_pattern"`
+	OnConflict   *conflictAction  `yaml:"on_conflict"`
+	Branches     *[]fileMergeRule `yaml:"branches"`
+}
+
+type fileMergeRule struct {
+	Branch   branchPattern  `yaml:"branch"`
+	Strategy *mergeStrategy `yaml:"strategy"`
+	Message  *mergeMessage  `yaml:"message"`
+}
+
+type fileSigning struct {
+	Tags           *bool            `yaml:"tags"`
+	Format         *signingFormat   `yaml:"format"`
+	Key            *nonEmptyString  `yaml:"key"`
+	AllowedSigners *nonEmptyString  `yaml:"allowed_signers"`
+	AllowedKeys    []nonEmptyString `yaml:"allowed_keys"`
+}
+
+type fileHost struct {
+	Host     hostName        `yaml:"host"`
+	Provider releaseProvider `yaml:"provider"`
+	APIURL   *apiURL         `yaml:"api_url"`
+}
+
+type fileComponent struct {
+	Name         *nonEmptyString `yaml:"name"`
+	Path         componentPath   `yaml:"path"`
+	TagPrefix    *tagPrefix      `yaml:"tag_prefix"`
+	VersionFiles []versionFile   `yaml:"version_files"`
+}
+
+type fileChannel struct {
+	Branch     branchPattern        `yaml:"branch"`
+	Identifier prereleaseIdentifier `yaml:"identifier"`
+}
+
+type fileChangelog struct {
+	File    *nonEmptyString `yaml:"file"`
+	GroupBy *groupBy        `yaml:"group_by"`
+}
+
+type fileBumpRule struct {
+	Branch branchPattern `yaml:"branch"`
+	Bump   bumpType      `yaml:"bump"`
+}
+
+type fileRelease struct {
+	Provider   *releaseProvider `yaml:"provider"`
+	Assets     *[]assetPattern  `yaml:"assets"`
+	OnExisting *existingPolicy  `yaml:"on_existing"`
+	Draft      *bool            `yaml:"draft"`
+	MakeLatest *makeLatest      `yaml:"make_latest"`
+}
+
+// UserFilePath returns the user-level configuration file, following the XDG
+// base directory spec: $XDG_CONFIG_HOME/version-manager/config.yml.
+func UserFilePath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "version-manager", "config.yml")
+}
+
+// FindRepoFile looks for RepoFileName in dir and its parents, stopping at the
+// repository root (the first directory containing .git). It returns "" when
+// no file is found.
+func FindRepoFile(dir string) string {
+	for {
+		candidate := filepath.Join(dir, RepoFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadFile applies the configuration file at filePath on top of c and returns
+// the keys it set. A missing file is not an error.
+func (c *Config) LoadFile(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", filePath, err)
+	}
+
+	return c.loadYAML(filePath, data)
+}
+
+func (c *Config) loadYAML(filePath string, data []byte) ([]string, error) {
+	var fc fileConfig
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&fc); err != nil && err != io.EOF {
+		return nil, fileError(filePath, err)
+	}
+
+	// The document as nodes locates the entries missing a required key.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fileError(filePath, err)
+	}
+
+	var keys []string
+
+	if fc.Remote != nil {
+		c.Remote = string(*fc.Remote)
+		keys = append(keys, KeyRemote)
+	}
+
+	if fc.ProtectedBranches != nil {
+		c.ProtectedBranches = make([]string, 0, len(*fc.ProtectedBranches))
+		for _, branch := range *fc.ProtectedBranches {
+			c.ProtectedBranches = append(c.ProtectedBranches, string(branch))
+		}
+		keys = append(keys, KeyProtectedBranches)
+	}
+
+	if fc.BumpRules != nil {
+		c.BumpRules = make([]BumpRule, 0, len(*fc.BumpRules))
+		for i, rule := range *fc.BumpRules {
+			if rule.Branch == "" || rule.Bump == "" {
+				return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "bump_rules"), "regra de versão sem \"branch\" ou \"bump\""))
+			}
+			c.BumpRules = append(c.BumpRules, BumpRule{Branch: string(rule.Branch), Bump: string(rule.Bump)})
+		}
+		keys = append(keys, KeyBumpRules)
+	}
+
+	if fc.Channels != nil {
+		c.Channels = make([]Channel, 0, len(*fc.Channels))
+		for i, channel := range *fc.Channels {
+			if channel.Branch == "" || channel.Identifier == "" {
+				return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "channels"), "canal sem \"branch\" ou \"identifier\""))
+			}
+			c.Channels = append(c.Channels, Channel{Branch: string(channel.Branch), Identifier: string(channel.Identifier)})
+		}
+		keys = append(keys, KeyChannels)
+	}
+
+	if fc.Components != nil {
+		c.Components = make([]Component, 0, len(*fc.Components))
+		for i, fileComp := range *fc.Components {
+			if fileComp.Path == "" {
+				return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "components"), "componente sem \"path\""))
+			}
+			component := Component{Path: string(fileComp.Path), TagPrefix: string(fileComp.Path) + "/v", Name: string(fileComp.Path)}
+			if fileComp.Name != nil {
+				component.Name = string(*fileComp.Name)
+			}
+			if fileComp.TagPrefix != nil {
+				component.TagPrefix = string(*fileComp.TagPrefix)
+			}
+			component.VersionFiles = versionFileSpecs(fileComp.VersionFiles)
+			c.Components = append(c.Components, component)
+		}
+		keys = append(keys, KeyComponents)
+	}
+
+	if fc.VersionFiles != nil {
+		c.VersionFiles = versionFileSpecs(*fc.VersionFiles)
+		keys = append(keys, KeyVersionFiles)
+	}
+
+	if fc.Hosts != nil {
+		c.Hosts = make([]Host, 0, len(*fc.Hosts))
+		for i, fileHost := range *fc.Hosts {
+			if fileHost.Host == "" || fileHost.Provider == "" {
+				return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "hosts"), "host precisa de \"host\" e \"provider\""))
+			}
+			host := Host{Name: string(fileHost.Host), Provider: string(fileHost.Provider)}
+			if fileHost.APIURL != nil {
+				host.APIURL = string(*fileHost.APIURL)
+			}
+			c.Hosts = append(c.Hosts, host)
+		}
+		keys = append(keys, KeyHosts)
+	}
+
+	if fc.Release != nil && fc.Release.Provider != nil {
+		c.RepoType = string(*fc.Release.Provider)
+		keys = append(keys, KeyReleaseProvider)
+	}
+
+	if fc.Release != nil && fc.Release.Assets != nil {
+		c.ReleaseAssets = make([]string, 0, len(*fc.Release.Assets))
+		for _, pattern := range *fc.Release.Assets {
+			c.ReleaseAssets = append(c.ReleaseAssets, string(pattern))
+		}
+		keys = append(keys, KeyReleaseAssets)
+	}
+
+	if fc.Release != nil && fc.Release.OnExisting != nil {
+		c.ReleaseExisting = string(*fc.Release.OnExisting)
+		keys = append(keys, KeyReleaseExisting)
+	}
+
+	if fc.Release != nil && fc.Release.Draft != nil {
+		c.ReleaseDraft = *fc.Release.Draft
+		keys = append(keys, KeyReleaseDraft)
+	}
+
+	if fc.Release != nil && fc.Release.MakeLatest != nil {
+		c.ReleaseMakeLatest = string(*fc.Release.MakeLatest)
+		keys = append(keys, KeyReleaseMakeLatest)
+	}
+
+	if fc.Signing != nil {
+		if fc.Signing.Tags != nil {
+			c.Signing.Tags = *fc.Signing.Tags
+		}
+		if fc.Signing.Format != nil {
+			c.Signing.Format = string(*fc.Signing.Format)
+		}
+		if fc.Signing.Key != nil {
+			c.Signing.Key = string(*fc.Signing.Key)
+		}
+		if fc.Signing.AllowedSigners != nil {
+			c.Signing.AllowedSigners = string(*fc.Signing.AllowedSigners)
+		}
+		if fc.Signing.AllowedKeys != nil {
+			c.Signing.AllowedKeys = make([]string, 0, len(fc.Signing.AllowedKeys))
+			for _, key := range fc.Signing.AllowedKeys {
+				c.Signing.AllowedKeys = append(c.Signing.AllowedKeys, string(key))
+			}
+		}
+		keys = append(keys, KeySigning)
+	}
+
+	if fc.Merge != nil {
+		if fc.Merge.Strategy != nil {
+			c.Merge.Strategy = string(*fc.Merge.Strategy)
+		}
+		if fc.Merge.Message != nil {
+			c.Merge.Message = string(*fc.Merge.Message)
+		}
+		if fc.Merge.IssuePattern != nil {
+			c.Merge.IssuePattern = string(*fc.Merge.IssuePattern)
+		}
+		if fc.Merge.OnConflict != nil {
+			c.Merge.OnConflict = string(*fc.Merge.OnConflict)
+		}
+		if fc.Merge.Branches != nil {
+			c.Merge.Rules = make([]MergeRule, 0, len(*fc.Merge.Branches))
+			for i, fileRule := range *fc.Merge.Branches {
+				if fileRule.Branch == "" {
+					return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "merge", "branches"), "regra de merge sem \"branch\""))
+				}
+				rule := MergeRule{Branch: string(fileRule.Branch)}
+				if fileRule.Strategy != nil {
+					rule.Strategy = string(*fileRule.Strategy)
+				}
+				if fileRule.Message != nil {
+					rule.Message = string(*fileRule.Message)
+				}
+				c.Merge.Rules = append(c.Merge.Rules, rule)
+			}
+		}
+		keys = append(keys, KeyMerge)
+	}
+
+	if fc.PullRequest != nil {
+		if fc.PullRequest.Enabled != nil {
+			c.PullRequest.Enabled = *fc.PullRequest.Enabled
+		}
+		if fc.PullRequest.AutoMerge != nil {
+			c.PullRequest.AutoMerge = *fc.PullRequest.AutoMerge
+		}
+		if fc.PullRequest.Wait != nil {
+			c.PullRequest.Wait = *fc.PullRequest.Wait
+		}
+		if fc.PullRequest.Timeout != nil {
+			c.PullRequest.Timeout = time.Duration(*fc.PullRequest.Timeout)
+		}
+		keys = append(keys, KeyPullRequest)
+	}
+
+	if fc.DeleteRemote != nil {
+		c.DeleteRemoteBranch = *fc.DeleteRemote
+		keys = append(keys, KeyDeleteRemote)
+	}
+
+	if fc.Policy != nil {
+		if fc.Policy.Promotions != nil {
+			c.Policy.Promotions = make([]PromotionRule, 0, len(*fc.Policy.Promotions))
+			for i, fileRule := range *fc.Policy.Promotions {
+				if fileRule.To == "" || len(fileRule.From) == 0 {
+					return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "policy", "promotions"), "regra de promoção sem \"to\" ou \"from\""))
+				}
+				rule := PromotionRule{To: string(fileRule.To)}
+				for _, from := range fileRule.From {
+					rule.From = append(rule.From, string(from))
+				}
+				c.Policy.Promotions = append(c.Policy.Promotions, rule)
+			}
+		}
+		if fc.Policy.Bumps != nil {
+			c.Policy.Bumps = make([]BumpPolicy, 0, len(*fc.Policy.Bumps))
+			for i, fileRule := range *fc.Policy.Bumps {
+				if fileRule.Branch == "" || len(fileRule.Allow) == 0 {
+					return nil, fileError(filePath, invalidValue(entryNode(&doc, i, "policy", "bumps"), "política de versão sem \"branch\" ou \"allow\""))
+				}
+				rule := BumpPolicy{Branch: string(fileRule.Branch)}
+				for _, bump := range fileRule.Allow {
+					rule.Allow = append(rule.Allow, string(bump))
+				}
+				c.Policy.Bumps = append(c.Policy.Bumps, rule)
+			}
+		}
+		if fc.Policy.NeverDelete != nil {
+			c.Policy.NeverDelete = make([]string, 0, len(*fc.Policy.NeverDelete))
+			for _, pattern := range *fc.Policy.NeverDelete {
+				c.Policy.NeverDelete = append(c.Policy.NeverDelete, string(pattern))
+			}
+		}
+		keys = append(keys, KeyPolicy)
+	}
+
+	if fc.TagPrefix != nil {
+		c.TagPrefix = string(*fc.TagPrefix)
+		keys = append(keys, KeyTagPrefix)
+	}
+
+	if fc.Changelog != nil {
+		if fc.Changelog.File != nil {
+			c.ChangelogFile = string(*fc.Changelog.File)
+		}
+		if fc.Changelog.GroupBy != nil {
+			c.ChangelogGroupBy = string(*fc.Changelog.GroupBy)
+		}
+		keys = append(keys, KeyChangelog)
+	}
+
+	return keys, nil
+}
+
+// LoadEnv applies the VERSION_MANAGER_* environment variables on top of c and
+// returns the keys it set.
+func (c *Config) LoadEnv() ([]string, error) {
+	var keys []string
+
+	if value, ok := os.LookupEnv("VERSION_MANAGER_REMOTE"); ok && value != "" {
+		c.Remote = value
+		keys = append(keys, KeyRemote)
+	}
+
+	if value, ok := os.LookupEnv("VERSION_MANAGER_PROTECTED_BRANCHES"); ok {
+		c.ProtectedBranches = splitList(value)
+		keys = append(keys, KeyProtectedBranches)
+	}
+
+	if value, ok := os.LookupEnv("VERSION_MANAGER_RELEASE_PROVIDER"); ok && value != "" {
+		provider := strings.ToLower(value)
+		if !contains(ReleaseProviders, provider) {
+			return nil, fmt.Errorf("VERSION_MANAGER_RELEASE_PROVIDER: provedor inválido %q (esperado: %s)", value, strings.Join(ReleaseProviders, ", "))
+		}
+		c.RepoType = provider
+		keys = append(keys, KeyReleaseProvider)
+	}
+
+	if value, ok := os.LookupEnv("VERSION_MANAGER_TAG_PREFIX"); ok {
+		if strings.ContainsAny(value, " \t\n~^:?*[\\") {
+			return nil, fmt.Errorf("VERSION_MANAGER_TAG_PREFIX: prefixo de tag inválido %q", value)
+		}
+		c.TagPrefix = value
+		keys = append(keys, KeyTagPrefix)
+	}
+
+	return keys, nil
+}
+
+var lineErrorPattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+var unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+
+// fileError rewrites yaml errors as "file:line: message", one per line.
+func fileError(filePath string, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Errorf("%s: %v", filePath, err)
+	}
+
+	messages := make([]string, 0, len(typeErr.Errors))
+	for _, raw := range typeErr.Errors {
+		match := lineErrorPattern.FindStringSubmatch(raw)
+		if match == nil {
+			messages = append(messages, fmt.Sprintf("%s: %s", filePath, raw))
+			continue
+		}
+
+		message := match[2]
+		if field := unknownFieldPattern.FindStringSubmatch(message); field != nil {
+			message = fmt.Sprintf("chave desconhecida %q", field[1])
+		}
+		messages = append(messages, fmt.Sprintf("%s:%s: %s", filePath, match[1], message))
+	}
+
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+func invalidValue(n *yaml.Node, format string, a ...interface{}) error {
+	return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: %s", n.Line, fmt.Sprintf(format, a...))}}
+}
+
+// entryNode returns the node of entry index of the sequence at path in doc,
+// or doc itself if there is no such entry.
+func entryNode(doc *yaml.Node, index int, path ...string) *yaml.Node {
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, key := range path {
+		if n.Kind != yaml.MappingNode {
+			return doc
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				value = n.Content[i+1]
+			}
+		}
+		if value == nil {
+			return doc
+		}
+		n = value
+	}
+	if n.Kind != yaml.SequenceNode || index >= len(n.Content) {
+		return doc
+	}
+	return n.Content[index]
+}
+
+func decodeString(n *yaml.Node) (string, error) {
+	if n.Kind != yaml.ScalarNode {
+		return "", invalidValue(n, "esperava um texto")
+	}
+	return n.Value, nil
+}
+
+type nonEmptyString string
+
+func (s *nonEmptyString) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(value) == "" {
+		return invalidValue(n, "valor vazio")
+	}
+	*s = nonEmptyString(value)
+	return nil
+}
+
+type branchName string
+
+func (b *branchName) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(value) == "" || strings.ContainsAny(value, " \t~^:?*[\\") {
+		return invalidValue(n, "nome de branch inválido %q", value)
+	}
+	*b = branchName(value)
+	return nil
+}
+
+type branchPattern string
+
+func (b *branchPattern) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if _, err := path.Match(value, ""); err != nil || value == "" {
+		return invalidValue(n, "padrão de branch inválido %q", value)
+	}
+	*b = branchPattern(value)
+	return nil
+}
+
+type bumpType string
+
+func (b *bumpType) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !version.IsBumpType(value) {
+		return invalidValue(n, "tipo de versão inválido %q (esperado: %s)", value, strings.Join(version.BumpTypes, ", "))
+	}
+	*b = bumpType(value)
+	return nil
+}
+
+var prereleaseIdentifierPattern = regexp.MustCompile(`^[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*$`)
+
+type prereleaseIdentifier string
+
+func (p *prereleaseIdentifier) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !prereleaseIdentifierPattern.MatchString(value) {
+		return invalidValue(n, "identificador de pré-lançamento inválido %q (use letras, números e hífens, ex.: alpha, rc)", value)
+	}
+	*p = prereleaseIdentifier(value)
+	return nil
+}
+
+type releaseProvider string
+
+func (p *releaseProvider) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	provider := strings.ToLower(value)
+	if !contains(ReleaseProviders, provider) {
+		return invalidValue(n, "provedor de release inválido %q (esperado: %s)", value, strings.Join(ReleaseProviders, ", "))
+	}
+	*p = releaseProvider(provider)
+	return nil
+}
+
+type signingFormat string
+
+func (f *signingFormat) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	format := strings.ToLower(value)
+	if format == "openpgp" {
+		format = SigningGPG
+	}
+	if !contains(SigningFormats, format) {
+		return invalidValue(n, "formato de assinatura inválido %q (esperado: %s)", value, strings.Join(SigningFormats, ", "))
+	}
+	*f = signingFormat(format)
+	return nil
+}
+
+type existingPolicy string
+
+func (p *existingPolicy) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !contains(ExistingReleasePolicies, value) {
+		return invalidValue(n, "política inválida %q para release existente (esperado: %s)", value, strings.Join(ExistingReleasePolicies, ", "))
+	}
+	*p = existingPolicy(value)
+	return nil
+}
+
+// makeLatest also accepts the YAML booleans true and false.
+type makeLatest string
+
+func (m *makeLatest) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !contains(MakeLatestValues, value) {
+		return invalidValue(n, "valor inválido %q para make_latest (esperado: %s)", value, strings.Join(MakeLatestValues, ", "))
+	}
+	*m = makeLatest(value)
+	return nil
+}
+
+type assetPattern string
+
+func (a *assetPattern) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return invalidValue(n, "padrão de asset vazio")
+	}
+	if _, err := filepath.Match(value, ""); err != nil {
+		return invalidValue(n, "padrão de asset inválido %q", value)
+	}
+	*a = assetPattern(value)
+	return nil
+}
+
+type mergeStrategy string
+
+func (m *mergeStrategy) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !contains(MergeStrategies, value) {
+		return invalidValue(n, "estratégia de merge inválida %q (esperado: %s)", value, strings.Join(MergeStrategies, ", "))
+	}
+	*m = mergeStrategy(value)
+	return nil
+}
+
+type conflictAction string
+
+func (a *conflictAction) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if !contains(ConflictActions, value) {
+		return invalidValue(n, "ação inválida %q para conflitos (esperado: %s)", value, strings.Join(ConflictActions, ", "))
+	}
+	*a = conflictAction(value)
+	return nil
+}
+
+type mergeMessage string
+
+func (m *mergeMessage) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(value) == "" {
+		return invalidValue(n, "mensagem de merge vazia")
+	}
+	if _, err := ParseMergeMessage(value); err != nil {
+		return invalidValue(n, "template de mensagem de merge inválido: %v", err)
+	}
+	*m = mergeMessage(value)
+	return nil
+}
+
+type issuePattern string
+
+func (p *issuePattern) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(value); err != nil || value == "" {
+		return invalidValue(n, "padrão de issue inválido %q", value)
+	}
+	*p = issuePattern(value)
+	return nil
+}
+
+type positiveDuration time.Duration
+
+func (d *positiveDuration) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return invalidValue(n, "duração inválida %q (ex.: 30m, 1h)", value)
+	}
+	*d = positiveDuration(duration)
+	return nil
+}
+
+type tagPrefix string
+
+func (t *tagPrefix) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(value, " \t\n~^:?*[\\") {
+		return invalidValue(n, "prefixo de tag inválido %q", value)
+	}
+	*t = tagPrefix(value)
+	return nil
+}
+
+type componentPath string
+
+func (p *componentPath) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	cleaned := path.Clean(strings.TrimSpace(value))
+	if value == "" || cleaned == "." || path.IsAbs(cleaned) || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return invalidValue(n, "caminho de componente inválido %q (use um caminho relativo à raiz do repositório)", value)
+	}
+	*p = componentPath(cleaned)
+	return nil
+}
+
+type versionFile bump.Spec
+
+func (v *versionFile) UnmarshalYAML(n *yaml.Node) error {
+	var raw struct {
+		Type    string `yaml:"type"`
+		Path    string `yaml:"path"`
+		Pattern string `yaml:"pattern"`
+		Const   string `yaml:"const"`
+	}
+	if n.Kind != yaml.MappingNode {
+		return invalidValue(n, "esperava um objeto com type e path")
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if key := n.Content[i]; !contains([]string{"type", "path", "pattern", "const"}, key.Value) {
+			return invalidValue(key, "chave desconhecida %q", key.Value)
+		}
+	}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+
+	if !contains(bump.Types, raw.Type) {
+		return invalidValue(n, "tipo de arquivo de versão inválido %q (esperado: %s)", raw.Type, strings.Join(bump.Types, ", "))
+	}
+
+	spec := bump.Spec{Type: raw.Type, Path: raw.Path, Pattern: raw.Pattern, Const: raw.Const}
+
+	// New validates the pattern and the path requirements of each type.
+	if _, err := bump.New(spec, ""); err != nil {
+		return invalidValue(n, "%v", err)
+	}
+
+	*v = versionFile(spec)
+	return nil
+}
+
+func versionFileSpecs(files []versionFile) []bump.Spec {
+	if files == nil {
+		return nil
+	}
+	specs := make([]bump.Spec, 0, len(files))
+	for _, file := range files {
+		specs = append(specs, bump.Spec(file))
+	}
+	return specs
+}
+
+type hostName string
+
+func (h *hostName) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if value == "" || strings.ContainsAny(value, "/: \t") {
+		return invalidValue(n, "host inválido %q (use apenas o nome, ex.: gitlab.example.com)", value)
+	}
+	*h = hostName(strings.ToLower(value))
+	return nil
+}
+
+type apiURL string
+
+func (a *apiURL) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return invalidValue(n, "URL da API inválida %q", value)
+	}
+	*a = apiURL(value)
+	return nil
+}
+
+type groupBy string
+
+func (g *groupBy) UnmarshalYAML(n *yaml.Node) error {
+	value, err := decodeString(n)
+	if err != nil {
+		return err
+	}
+	if value != "type" && value != "scope" {
+		return invalidValue(n, "agrupamento inválido %q (esperado: type, scope)", value)
+	}
+	*g = groupBy(value)
+	return nil
+}
+
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
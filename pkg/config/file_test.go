@@ -0,0 +1,396 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/bump"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), RepoFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Erro ao escrever arquivo de teste: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfigFile(t, `
+remote: upstream
+protected_branches: [main, release]
+bump_rules:
+  - branch: main
+    bump: minor
+  - branch: "hotfix/*"
+    bump: patch
+release:
+  provider: GitLab
+  assets: ["dist/*.tar.gz", "dist/*.zip"]
+  on_existing: update
+  draft: true
+  make_latest: false
+tag_prefix: v
+channels:
+  - branch: develop
+    identifier: alpha
+  - branch: "hotfix/*"
+    identifier: hotfix
+`)
+
+	cfg := NewConfig()
+	keys, err := cfg.LoadFile(path)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if len(keys) != 10 {
+		t.Errorf("Esperava 10 chaves carregadas, obteve %v", keys)
+	}
+
+	if cfg.Remote != "upstream" {
+		t.Errorf("Remote: esperava 'upstream', obteve '%s'", cfg.Remote)
+	}
+
+	if !cfg.IsProtected("release") || cfg.IsProtected("develop") {
+		t.Errorf("ProtectedBranches não foi substituído: %v", cfg.ProtectedBranches)
+	}
+
+	if bump := cfg.BumpFor("hotfix/login"); bump != "patch" {
+		t.Errorf("BumpFor(hotfix/login): esperava 'patch', obteve '%s'", bump)
+	}
+
+	if bump := cfg.BumpFor("develop"); bump != "" {
+		t.Errorf("BumpFor(develop): esperava vazio, obteve '%s'", bump)
+	}
+
+	if cfg.RepoType != "gitlab" {
+		t.Errorf("RepoType: esperava 'gitlab', obteve '%s'", cfg.RepoType)
+	}
+
+	if strings.Join(cfg.ReleaseAssets, ",") != "dist/*.tar.gz,dist/*.zip" {
+		t.Errorf("ReleaseAssets: esperava os padrões do arquivo, obteve %v", cfg.ReleaseAssets)
+	}
+
+	if cfg.ReleaseExisting != ExistingUpdate || !cfg.ReleaseDraft || cfg.ReleaseMakeLatest != "false" {
+		t.Errorf("Opções da release não carregadas: %q %v %q", cfg.ReleaseExisting, cfg.ReleaseDraft, cfg.ReleaseMakeLatest)
+	}
+
+	if cfg.TagPrefix != "v" {
+		t.Errorf("TagPrefix: esperava 'v', obteve '%s'", cfg.TagPrefix)
+	}
+
+	if channel := cfg.ChannelFor("hotfix/login"); channel != "hotfix" {
+		t.Errorf("ChannelFor(hotfix/login): esperava 'hotfix', obteve '%s'", channel)
+	}
+
+	if channel := cfg.ChannelFor("stage"); channel != "" {
+		t.Errorf("Canais padrão deveriam ser substituídos, ChannelFor(stage) obteve '%s'", channel)
+	}
+}
+
+func TestLoadFileComponents(t *testing.T) {
+	path := writeConfigFile(t, `
+components:
+  - name: billing
+    path: services/billing/
+  - path: libs/auth
+    tag_prefix: auth-v
+    version_files:
+      - type: package.json
+`)
+
+	cfg := NewConfig()
+	if _, err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := []Component{
+		{Name: "billing", Path: "services/billing", TagPrefix: "services/billing/v"},
+		{Name: "libs/auth", Path: "libs/auth", TagPrefix: "auth-v", VersionFiles: []bump.Spec{{Type: bump.TypePackageJSON}}},
+	}
+
+	if len(cfg.Components) != len(expected) {
+		t.Fatalf("Esperava %d componentes, obteve %v", len(expected), cfg.Components)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(cfg.Components[i], expected[i]) {
+			t.Errorf("Componente %d: esperava %+v, obteve %+v", i, expected[i], cfg.Components[i])
+		}
+	}
+}
+
+func TestLoadFileHosts(t *testing.T) {
+	path := writeConfigFile(t, `
+hosts:
+  - host: git.example.com
+    provider: gitlab
+  - host: GHE.example.com
+    provider: github
+    api_url: https://ghe.example.com/custom/api/
+`)
+
+	cfg := NewConfig()
+	if _, err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	testCases := []struct {
+		host     string
+		provider string
+		apiURL   string
+	}{
+		{"github.com", "github", "https://api.github.com"},
+		{"gitlab.com", "gitlab", "https://gitlab.com/api/v4"},
+		{"gitlab.acme.io", "gitlab", "https://gitlab.acme.io/api/v4"},
+		{"git.example.com", "gitlab", "https://git.example.com/api/v4"},
+		{"ghe.example.com", "github", "https://ghe.example.com/custom/api"},
+		{"codeberg.org", "forgejo", "https://codeberg.org/api/v1"},
+		{"gitea.acme.io", "gitea", "https://gitea.acme.io/api/v1"},
+		{"bitbucket.org", "bitbucket", "https://api.bitbucket.org/2.0"},
+		{"bitbucket.acme.io", "bitbucket-server", "https://bitbucket.acme.io/rest/api/1.0"},
+		{"code.example.com", "", ""},
+	}
+
+	for _, tc := range testCases {
+		provider := cfg.ProviderFor(tc.host)
+		if provider != tc.provider {
+			t.Errorf("ProviderFor(%s): esperava '%s', obteve '%s'", tc.host, tc.provider, provider)
+		}
+		if apiURL := cfg.APIURLFor(tc.host, provider); apiURL != tc.apiURL {
+			t.Errorf("APIURLFor(%s): esperava '%s', obteve '%s'", tc.host, tc.apiURL, apiURL)
+		}
+	}
+
+	// Um provedor informado explicitamente usa a API padrão do host
+	if apiURL := cfg.APIURLFor("code.example.com", "github"); apiURL != "https://code.example.com/api/v3" {
+		t.Errorf("APIURLFor GitHub Enterprise: obteve '%s'", apiURL)
+	}
+}
+
+func TestLoadFileSigning(t *testing.T) {
+	path := writeConfigFile(t, `
+signing:
+  tags: true
+  format: openpgp
+  key: DEADBEEFCAFE0001
+  allowed_signers: .allowed_signers
+  allowed_keys: [DEADBEEFCAFE0001, 0123456789ABCDEF]
+`)
+
+	cfg := NewConfig()
+	keys, err := cfg.LoadFile(path)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := Signing{
+		Tags:           true,
+		Format:         SigningGPG,
+		Key:            "DEADBEEFCAFE0001",
+		AllowedSigners: ".allowed_signers",
+		AllowedKeys:    []string{"DEADBEEFCAFE0001", "0123456789ABCDEF"},
+	}
+	if !reflect.DeepEqual(cfg.Signing, expected) || !reflect.DeepEqual(keys, []string{KeySigning}) {
+		t.Errorf("Esperava %+v, obteve %+v (chaves %v)", expected, cfg.Signing, keys)
+	}
+}
+
+func TestLoadFileMerge(t *testing.T) {
+	path := writeConfigFile(t, `
+merge:
+  strategy: no-ff
+  message: "Merge {{.Source}} ({{.Version}})"
+  issue_pattern: "#[0-9]+"
+  on_conflict: mergetool
+  branches:
+    - branch: main
+      strategy: squash
+    - branch: release/*
+      message: "Promote {{.Source}}"
+`)
+
+	cfg := NewConfig()
+	if _, err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	testCases := []struct {
+		branch   string
+		strategy string
+		message  string
+	}{
+		{"main", MergeSquash, "Merge {{.Source}} ({{.Version}})"},
+		{"release/2.x", MergeNoFastForward, "Promote {{.Source}}"},
+		{"stage", MergeNoFastForward, "Merge {{.Source}} ({{.Version}})"},
+	}
+
+	for _, tc := range testCases {
+		if strategy := cfg.MergeStrategyFor(tc.branch); strategy != tc.strategy {
+			t.Errorf("MergeStrategyFor(%s): esperava '%s', obteve '%s'", tc.branch, tc.strategy, strategy)
+		}
+		if message := cfg.MergeMessageFor(tc.branch); message != tc.message {
+			t.Errorf("MergeMessageFor(%s): esperava '%s', obteve '%s'", tc.branch, tc.message, message)
+		}
+	}
+
+	if cfg.Merge.IssuePattern != "#[0-9]+" || cfg.Merge.OnConflict != ConflictMergetool {
+		t.Errorf("Opções de merge inesperadas: %+v", cfg.Merge)
+	}
+
+	if strategy := NewConfig().MergeStrategyFor("main"); strategy != MergeDefault {
+		t.Errorf("Sem configuração, esperava a estratégia '%s', obteve '%s'", MergeDefault, strategy)
+	}
+}
+
+func TestLoadFilePullRequest(t *testing.T) {
+	path := writeConfigFile(t, `
+pull_request:
+  enabled: true
+  auto_merge: true
+  timeout: 1h
+`)
+
+	cfg := NewConfig()
+	keys, err := cfg.LoadFile(path)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := PullRequest{Enabled: true, AutoMerge: true, Wait: true, Timeout: time.Hour}
+	if cfg.PullRequest != expected || !reflect.DeepEqual(keys, []string{KeyPullRequest}) {
+		t.Errorf("Esperava %+v, obteve %+v (chaves %v)", expected, cfg.PullRequest, keys)
+	}
+}
+
+func TestLoadFilePolicy(t *testing.T) {
+	path := writeConfigFile(t, `
+policy:
+  promotions:
+    - to: main
+      from: [stage]
+    - to: stage
+      from: [develop, "hotfix/*"]
+  bumps:
+    - branch: main
+      allow: [minor, patch]
+  never_delete: ["release/*"]
+`)
+
+	cfg := NewConfig()
+	if _, err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if sources, restricted := cfg.PromotionSources("stage"); !restricted || strings.Join(sources, ",") != "develop,hotfix/*" {
+		t.Errorf("PromotionSources(stage): obteve %v %v", sources, restricted)
+	}
+	if _, restricted := cfg.PromotionSources("develop"); restricted {
+		t.Error("develop não tem regra de promoção e não deveria ser restrita")
+	}
+	if bumps, restricted := cfg.AllowedBumps("main"); !restricted || strings.Join(bumps, ",") != "minor,patch" {
+		t.Errorf("AllowedBumps(main): obteve %v %v", bumps, restricted)
+	}
+	if !cfg.IsProtected("release/2.x") || !cfg.IsProtected("main") || cfg.IsProtected("feature/x") {
+		t.Errorf("IsProtected deveria considerar protected_branches e never_delete: %+v", cfg.Policy)
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg := NewConfig()
+
+	keys, err := cfg.LoadFile(filepath.Join(t.TempDir(), "missing.yml"))
+	if err != nil || keys != nil {
+		t.Errorf("Arquivo inexistente deve ser ignorado, obteve keys=%v err=%v", keys, err)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"Unknown Key", "remote: origin\nremotes: other\n", []string{":2: chave desconhecida \"remotes\""}},
+		{"Invalid Bump", "bump_rules:\n  - branch: main\n    bump: huge\n", []string{":3: tipo de versão inválido \"huge\""}},
+		{"Bump Rule Without Bump", "bump_rules:\n  - branch: main\n", []string{":2: regra de versão sem \"branch\" ou \"bump\""}},
+		{"Bump Rule Without Branch", "bump_rules:\n  - branch: main\n    bump: minor\n  - bump: patch\n", []string{":4: regra de versão sem \"branch\" ou \"bump\""}},
+		{"Invalid Provider", "release:\n  provider: svn\n", []string{":2: provedor de release inválido \"svn\""}},
+		{"Invalid Asset Pattern", "release:\n  assets: [\"dist/[*.zip\"]\n", []string{":2: padrão de asset inválido \"dist/[*.zip\""}},
+		{"Invalid Existing Policy", "release:\n  on_existing: replace\n", []string{":2: política inválida \"replace\" para release existente"}},
+		{"Invalid Make Latest", "release:\n  make_latest: maybe\n", []string{":2: valor inválido \"maybe\" para make_latest"}},
+		{"Invalid Signing Format", "signing:\n  format: x509\n", []string{":2: formato de assinatura inválido \"x509\""}},
+		{"Invalid Merge Strategy", "merge:\n  strategy: octopus\n", []string{":2: estratégia de merge inválida \"octopus\""}},
+		{"Invalid Merge Message", "merge:\n  message: \"Merge {{.Source\"\n", []string{":2: template de mensagem de merge inválido"}},
+		{"Invalid Issue Pattern", "merge:\n  issue_pattern: \"[A-Z\"\n", []string{":2: padrão de issue inválido"}},
+		{"Invalid Conflict Action", "merge:\n  on_conflict: theirs\n", []string{":2: ação inválida \"theirs\" para conflitos"}},
+		{"Invalid Wait Timeout", "pull_request:\n  timeout: -5m\n", []string{":2: duração inválida \"-5m\""}},
+		{"Invalid Allowed Bump", "policy:\n  bumps:\n    - branch: main\n      allow: [huge]\n", []string{":4: tipo de versão inválido \"huge\""}},
+		{"Promotion Without From", "policy:\n  promotions:\n    - to: main\n", []string{":3: regra de promoção sem \"to\" ou \"from\""}},
+		{"Bump Policy Without Allow", "policy:\n  bumps:\n    - branch: main\n", []string{":3: política de versão sem \"branch\" ou \"allow\""}},
+		{"Merge Rule Without Branch", "merge:\n  branches:\n    - strategy: squash\n", []string{":3: regra de merge sem \"branch\""}},
+		{"Invalid Channel", "channels:\n  - branch: develop\n    identifier: \"1\"\n", []string{":3: identificador de pré-lançamento inválido \"1\""}},
+		{"Channel Without Identifier", "channels:\n  - branch: develop\n", []string{":2: canal sem \"branch\" ou \"identifier\""}},
+		{"Channel Without Branch", "channels:\n  - identifier: rc\n", []string{":2: canal sem \"branch\" ou \"identifier\""}},
+		{"Invalid Component Path", "components:\n  - name: api\n    path: ../api\n", []string{":3: caminho de componente inválido \"../api\""}},
+		{"Invalid Version File Type", "version_files:\n  - type: gradle\n", []string{":2: tipo de arquivo de versão inválido \"gradle\""}},
+		{"Regex Without Group", "version_files:\n  - type: regex\n    path: v.h\n    pattern: VERSION\n", []string{":2: pattern \"VERSION\" precisa de um grupo de captura"}},
+		{"Version File Unknown Key", "version_files:\n  - type: version\n    file: VERSION\n", []string{":3: chave desconhecida \"file\""}},
+		{"Invalid Host", "hosts:\n  - host: https://git.example.com\n    provider: gitlab\n", []string{":2: host inválido"}},
+		{"Invalid API URL", "hosts:\n  - host: git.example.com\n    provider: github\n    api_url: git.example.com/api\n", []string{":4: URL da API inválida"}},
+		{"Component Without Path", "components:\n  - name: api\n", []string{":2: componente sem \"path\""}},
+		{"Host Without Provider", "hosts:\n  - host: git.example.com\n    api_url: https://git.example.com/api\n", []string{":2: host precisa de \"host\" e \"provider\""}},
+		{"Several Errors", "tag_prefix: \"a b\"\nfoo: 1\n", []string{":1: prefixo de tag inválido", ":2: chave desconhecida \"foo\""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeConfigFile(t, tc.content)
+
+			_, err := NewConfig().LoadFile(path)
+			if err == nil {
+				t.Fatal("Esperava erro, obteve nil")
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(err.Error(), path+expected) {
+					t.Errorf("Erro deveria conter '%s', obteve: %v", path+expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, "remote: upstream\ntag_prefix: v\n")
+
+	cfg := NewConfig()
+	if _, err := cfg.LoadFile(path); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	t.Setenv("VERSION_MANAGER_REMOTE", "origin")
+	t.Setenv("VERSION_MANAGER_PROTECTED_BRANCHES", "main, stage")
+
+	if _, err := cfg.LoadEnv(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cfg.Remote != "origin" {
+		t.Errorf("Remote: esperava 'origin', obteve '%s'", cfg.Remote)
+	}
+
+	if len(cfg.ProtectedBranches) != 2 || cfg.ProtectedBranches[1] != "stage" {
+		t.Errorf("ProtectedBranches: esperava [main stage], obteve %v", cfg.ProtectedBranches)
+	}
+
+	if cfg.TagPrefix != "v" {
+		t.Errorf("TagPrefix do arquivo deveria ser mantido, obteve '%s'", cfg.TagPrefix)
+	}
+}
@@ -0,0 +1,164 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFileName is the name of the checksums asset uploaded with the
+// release assets, in the format of the sha256sum tool.
+const ChecksumsFileName = "SHA256SUMS"
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string
+	Path string
+
+	// Data, when set, is uploaded instead of the file at Path.
+	Data []byte
+}
+
+func (a Asset) read() ([]byte, error) {
+	if a.Data != nil {
+		return a.Data, nil
+	}
+	data, err := os.ReadFile(a.Path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", a.Path, err)
+	}
+	return data, nil
+}
+
+func (a Asset) contentType() string {
+	if contentType := mime.TypeByExtension(filepath.Ext(a.Name)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// multipartFile returns a multipart/form-data body holding content as the
+// file name in field, and its content type.
+func multipartFile(field string, name string, content []byte) (string, []byte, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	part, err := form.CreateFormFile(field, name)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", nil, err
+	}
+	if err := form.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return form.FormDataContentType(), body.Bytes(), nil
+}
+
+// AssetUploader is implemented by the providers that can attach files to a
+// release. UploadAsset returns the download URL of the asset, when known.
+type AssetUploader interface {
+	UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error)
+}
+
+// ResolveAssets expands the glob patterns into the files to upload, sorted by
+// name. A pattern matching no file, or two files with the same name, is an
+// error; directories are skipped.
+func ResolveAssets(patterns []string) ([]Asset, error) {
+	byName := map[string]Asset{}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("padrão de asset inválido %q: %v", pattern, err)
+		}
+
+		found := false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			found = true
+
+			asset := Asset{Name: filepath.Base(match), Path: match}
+			if existing, ok := byName[asset.Name]; ok && existing.Path != asset.Path {
+				return nil, fmt.Errorf("dois assets com o mesmo nome %s: %s e %s", asset.Name, existing.Path, asset.Path)
+			}
+			byName[asset.Name] = asset
+		}
+
+		if !found {
+			return nil, fmt.Errorf("nenhum arquivo corresponde ao padrão de asset %q", pattern)
+		}
+	}
+
+	assets := make([]Asset, 0, len(byName))
+	for _, asset := range byName {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+
+	return assets, nil
+}
+
+// Checksums returns the SHA256SUMS asset for assets.
+func Checksums(assets []Asset) (Asset, error) {
+	var sums strings.Builder
+
+	for _, asset := range assets {
+		hash := sha256.New()
+
+		if asset.Data != nil {
+			hash.Write(asset.Data)
+		} else {
+			file, err := os.Open(asset.Path)
+			if err != nil {
+				return Asset{}, fmt.Errorf("erro ao ler %s: %v", asset.Path, err)
+			}
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return Asset{}, fmt.Errorf("erro ao ler %s: %v", asset.Path, err)
+			}
+		}
+
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(hash.Sum(nil)), asset.Name)
+	}
+
+	return Asset{Name: ChecksumsFileName, Data: []byte(sums.String())}, nil
+}
+
+// assetSize formats the size of asset for progress messages.
+func assetSize(asset Asset) string {
+	size := int64(len(asset.Data))
+	if asset.Data == nil {
+		info, err := os.Stat(asset.Path)
+		if err != nil {
+			return "?"
+		}
+		size = info.Size()
+	}
+
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,215 @@
+package release
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/be-tech/version-manager/internal/utils"
+)
+
+func writeAssetFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolveAssetsAndChecksums(t *testing.T) {
+	dir := writeAssetFiles(t, map[string]string{
+		"dist/app-linux.tar.gz": "linux",
+		"dist/app-darwin.zip":   "darwin",
+		"dist/notes.txt":        "notas",
+	})
+
+	assets, err := ResolveAssets([]string{
+		filepath.Join(dir, "dist", "*.tar.gz"),
+		filepath.Join(dir, "dist", "*.zip"),
+		filepath.Join(dir, "dist", "app-*"),
+	})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	var names []string
+	for _, asset := range assets {
+		names = append(names, asset.Name)
+	}
+	if strings.Join(names, ",") != "app-darwin.zip,app-linux.tar.gz" {
+		t.Errorf("Esperava os assets ordenados e sem repetição, obteve %v", names)
+	}
+
+	checksums, err := Checksums(assets)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if checksums.Name != ChecksumsFileName {
+		t.Errorf("Esperava o nome %s, obteve %s", ChecksumsFileName, checksums.Name)
+	}
+
+	sum := sha256.Sum256([]byte("darwin"))
+	firstLine := hex.EncodeToString(sum[:]) + "  app-darwin.zip\n"
+	if !strings.HasPrefix(string(checksums.Data), firstLine) {
+		t.Errorf("Esperava o SHA256SUMS começando com %q, obteve %q", firstLine, checksums.Data)
+	}
+	if strings.Count(string(checksums.Data), "\n") != 2 {
+		t.Errorf("Esperava uma linha por asset, obteve %q", checksums.Data)
+	}
+}
+
+func TestResolveAssetsErrors(t *testing.T) {
+	dir := writeAssetFiles(t, map[string]string{
+		"a/app.zip": "a",
+		"b/app.zip": "b",
+	})
+
+	_, err := ResolveAssets([]string{filepath.Join(dir, "*.exe")})
+	if err == nil || !strings.Contains(err.Error(), "*.exe") {
+		t.Errorf("Esperava erro citando o padrão sem arquivos, obteve %v", err)
+	}
+
+	_, err = ResolveAssets([]string{filepath.Join(dir, "*", "app.zip")})
+	if err == nil || !strings.Contains(err.Error(), "mesmo nome app.zip") {
+		t.Errorf("Esperava erro de nomes repetidos, obteve %v", err)
+	}
+}
+
+func TestGitHubUploadAsset(t *testing.T) {
+	var server *httptest.Server
+	var uploaded []string
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/acme/widgets/releases":
+			writeJSON(w, http.StatusCreated, map[string]interface{}{
+				"id":         7,
+				"tag_name":   "v1.0.0",
+				"upload_url": server.URL + "/uploads/repos/acme/widgets/releases/7/assets{?name,label}",
+			})
+		case "POST /uploads/repos/acme/widgets/releases/7/assets":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = append(uploaded, r.URL.Query().Get("name")+"="+string(body)+" "+r.Header.Get("Content-Type"))
+			writeJSON(w, http.StatusCreated, map[string]string{"browser_download_url": "https://example.com/" + r.URL.Query().Get("name")})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGitHub(server.Client(), server.URL, "acme/widgets", "secret")
+	created, err := provider.Create(context.Background(), &Release{TagName: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	assetURL, err := provider.UploadAsset(context.Background(), created, Asset{Name: "SHA256SUMS", Data: []byte("abc")})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if assetURL != "https://example.com/SHA256SUMS" {
+		t.Errorf("URL do asset inesperada: %s", assetURL)
+	}
+	if len(uploaded) != 1 || uploaded[0] != "SHA256SUMS=abc application/octet-stream" {
+		t.Errorf("Upload inesperado: %v", uploaded)
+	}
+}
+
+func TestGitHubUploadURLFallback(t *testing.T) {
+	release := &Release{ID: "7"}
+
+	public := NewGitHub(nil, "https://api.github.com", "acme/widgets", "")
+	if got := public.uploadURL(release); got != "https://uploads.github.com/repos/acme/widgets/releases/7/assets" {
+		t.Errorf("URL de upload inesperada: %s", got)
+	}
+
+	enterprise := NewGitHub(nil, "https://github.example.com/api/v3", "acme/widgets", "")
+	if got := enterprise.uploadURL(release); got != "https://github.example.com/api/uploads/repos/acme/widgets/releases/7/assets" {
+		t.Errorf("URL de upload inesperada: %s", got)
+	}
+}
+
+func TestGitLabUploadAsset(t *testing.T) {
+	var requests []string
+	var link map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch {
+		case r.Method == http.MethodPut:
+			writeJSON(w, http.StatusCreated, map[string]string{"message": "201 Created"})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/assets/links"):
+			_ = json.NewDecoder(r.Body).Decode(&link)
+			writeJSON(w, http.StatusCreated, link)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not Found"})
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGitLab(server.Client(), server.URL, "group/widgets", "secret")
+	release := &Release{ID: "services/api/v1.0.0", TagName: "services/api/v1.0.0"}
+
+	assetURL, err := provider.UploadAsset(context.Background(), release, Asset{Name: "app.tar.gz", Data: []byte("bin")})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	expected := []string{
+		"PUT /projects/group%2Fwidgets/packages/generic/widgets/services-api-v1.0.0/app.tar.gz",
+		"POST /projects/group%2Fwidgets/releases/services%2Fapi%2Fv1.0.0/assets/links",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Esperava as requisições %v, obteve %v", expected, requests)
+	}
+	if link["name"] != "app.tar.gz" || link["url"] != assetURL || link["link_type"] != "package" {
+		t.Errorf("Link inesperado: %v (URL do asset %s)", link, assetURL)
+	}
+}
+
+type failingUploader struct {
+	uploaded []string
+	failOn   string
+}
+
+func (f *failingUploader) UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error) {
+	if asset.Name == f.failOn {
+		return "", errors.New("413 Request Entity Too Large")
+	}
+	f.uploaded = append(f.uploaded, asset.Name)
+	return "", nil
+}
+
+func TestUploadAssetsNamesFailingAsset(t *testing.T) {
+	r := &ReleaseManager{logger: utils.NewLogger()}
+	uploader := &failingUploader{failOn: "big.iso"}
+
+	assets := []Asset{
+		{Name: "app.zip", Data: []byte("a")},
+		{Name: "big.iso", Data: []byte("b")},
+		{Name: ChecksumsFileName, Data: []byte("c")},
+	}
+
+	err := r.uploadAssets(context.Background(), uploader, &Release{TagName: "v1.0.0"}, assets)
+	if err == nil || !strings.Contains(err.Error(), "big.iso (2/3)") || !strings.Contains(err.Error(), "413") {
+		t.Errorf("Esperava erro citando o asset big.iso, obteve %v", err)
+	}
+	if strings.Join(uploader.uploaded, ",") != "app.zip" {
+		t.Errorf("Esperava parar no asset com falha, enviados: %v", uploader.uploaded)
+	}
+}
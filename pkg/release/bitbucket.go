@@ -0,0 +1,340 @@
+package release
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Bitbucket has no release object. A release there is the annotated tag, its
+// notes uploaded to the Downloads area (Cloud only) and the notes posted as a
+// comment on the tagged commit.
+
+// bitbucketHeaders authenticates with an access token, or with basic auth
+// when token is "user:app-password".
+func bitbucketHeaders(token string) http.Header {
+	headers := http.Header{}
+	headers.Set("Accept", "application/json")
+	if strings.Contains(token, ":") {
+		headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(token)))
+	} else {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	return headers
+}
+
+// notesFileName is the name of the Downloads file holding the notes of tag.
+func notesFileName(tag string) string {
+	return "release-notes-" + strings.ReplaceAll(tag, "/", "-") + ".md"
+}
+
+func commentText(release *Release) string {
+	if release.Name == "" {
+		return release.Body
+	}
+	return "## " + release.Name + "\n\n" + release.Body
+}
+
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// BitbucketCloud manages releases on bitbucket.org.
+type BitbucketCloud struct {
+	api  *apiClient
+	repo string
+}
+
+// NewBitbucketCloud returns the Bitbucket Cloud provider for repo, e.g.
+// "workspace/widgets", using the API at apiURL, such as
+// https://api.bitbucket.org/2.0.
+func NewBitbucketCloud(client *http.Client, apiURL string, repo string, token string) *BitbucketCloud {
+	return &BitbucketCloud{api: newAPIClient(client, apiURL, bitbucketHeaders(token)), repo: repo}
+}
+
+func (b *BitbucketCloud) Name() string {
+	return "Bitbucket"
+}
+
+type bitbucketCloudTag struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Target  struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func (t *bitbucketCloudTag) release() *Release {
+	return &Release{
+		ID:      t.Name,
+		TagName: t.Name,
+		Name:    t.Name,
+		Body:    strings.TrimSpace(t.Message),
+		Target:  t.Target.Hash,
+		URL:     t.Links.HTML.Href,
+	}
+}
+
+func (b *BitbucketCloud) repoPath() string {
+	return "/repositories/" + b.repo
+}
+
+func (b *BitbucketCloud) Create(ctx context.Context, release *Release) (*Release, error) {
+	tag, err := b.tag(ctx, release.TagName)
+	if isNotFound(err) {
+		tag, err = b.createTag(ctx, release)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.publishNotes(ctx, tag.Target.Hash, release); err != nil {
+		return nil, err
+	}
+
+	created := tag.release()
+	created.Name, created.Body = release.Name, release.Body
+	return created, nil
+}
+
+func (b *BitbucketCloud) Get(ctx context.Context, tag string) (*Release, error) {
+	found, err := b.tag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return found.release(), nil
+}
+
+// Update uploads the notes again, replacing the Downloads file, and posts
+// them as a new comment.
+func (b *BitbucketCloud) Update(ctx context.Context, release *Release) (*Release, error) {
+	tag, err := b.tag(ctx, release.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.publishNotes(ctx, tag.Target.Hash, release); err != nil {
+		return nil, err
+	}
+
+	updated := tag.release()
+	updated.Name, updated.Body = release.Name, release.Body
+	return updated, nil
+}
+
+// Delete removes the notes from the Downloads area. The tag and the commit
+// comments are kept, as a GitHub release deletion keeps its tag.
+func (b *BitbucketCloud) Delete(ctx context.Context, tag string) error {
+	err := b.api.do(ctx, http.MethodDelete, b.repoPath()+"/downloads/"+url.PathEscape(notesFileName(tag)), nil, nil)
+	if isStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("%s: %w", tag, ErrNotFound)
+	}
+	return err
+}
+
+func (b *BitbucketCloud) tag(ctx context.Context, name string) (*bitbucketCloudTag, error) {
+	var tag bitbucketCloudTag
+	err := b.api.do(ctx, http.MethodGet, b.repoPath()+"/refs/tags/"+url.PathEscape(name), nil, &tag)
+	if isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// createTag pushes the annotated tag through the API, for when it was not
+// pushed with git first.
+func (b *BitbucketCloud) createTag(ctx context.Context, release *Release) (*bitbucketCloudTag, error) {
+	hash := release.Target
+	if !commitHashPattern.MatchString(hash) {
+		var branch struct {
+			Target struct {
+				Hash string `json:"hash"`
+			} `json:"target"`
+		}
+		if err := b.api.do(ctx, http.MethodGet, b.repoPath()+"/refs/branches/"+url.PathEscape(release.Target), nil, &branch); err != nil {
+			return nil, fmt.Errorf("falha ao resolver o commit de %s: %v", release.Target, err)
+		}
+		hash = branch.Target.Hash
+	}
+
+	body := map[string]interface{}{
+		"name":    release.TagName,
+		"message": "Version " + release.TagName,
+		"target":  map[string]string{"hash": hash},
+	}
+
+	var tag bitbucketCloudTag
+	if err := b.api.do(ctx, http.MethodPost, b.repoPath()+"/refs/tags", body, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// publishNotes uploads the notes to Downloads and comments them on commit.
+func (b *BitbucketCloud) publishNotes(ctx context.Context, commit string, release *Release) error {
+	if release.Body == "" {
+		return nil
+	}
+
+	if err := b.uploadDownload(ctx, notesFileName(release.TagName), []byte(release.Body)); err != nil {
+		return err
+	}
+
+	comment := map[string]interface{}{"content": map[string]string{"raw": commentText(release)}}
+	return b.api.do(ctx, http.MethodPost, b.repoPath()+"/commit/"+commit+"/comments", comment, nil)
+}
+
+// uploadDownload adds a file to the Downloads area, replacing any file with
+// the same name.
+func (b *BitbucketCloud) uploadDownload(ctx context.Context, name string, content []byte) error {
+	contentType, body, err := multipartFile("files", name, content)
+	if err != nil {
+		return err
+	}
+
+	if err := b.api.send(ctx, http.MethodPost, b.repoPath()+"/downloads", contentType, body, nil); err != nil {
+		return fmt.Errorf("falha ao enviar %s para Downloads: %w", name, err)
+	}
+	return nil
+}
+
+// UploadAsset adds asset to the Downloads area, next to the release notes.
+func (b *BitbucketCloud) UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error) {
+	data, err := asset.read()
+	if err != nil {
+		return "", err
+	}
+
+	contentType, body, err := multipartFile("files", asset.Name, data)
+	if err != nil {
+		return "", err
+	}
+
+	if err := b.api.send(ctx, http.MethodPost, b.repoPath()+"/downloads", contentType, body, nil); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// BitbucketServer manages releases on Bitbucket Server and Data Center, which
+// have no Downloads area: the notes only go to the tag's commit comment.
+type BitbucketServer struct {
+	api     *apiClient
+	project string
+	slug    string
+}
+
+// NewBitbucketServer returns the Bitbucket Server provider for repo, e.g.
+// "PROJ/widgets" or the "scm/PROJ/widgets" path of its clone URL, using the
+// API at apiURL, such as https://bitbucket.example.com/rest/api/1.0.
+func NewBitbucketServer(client *http.Client, apiURL string, repo string, token string) *BitbucketServer {
+	repo = strings.TrimPrefix(repo, "scm/")
+
+	project, slug := repo, ""
+	if i := strings.LastIndex(repo, "/"); i != -1 {
+		project, slug = repo[:i], repo[i+1:]
+	}
+
+	return &BitbucketServer{api: newAPIClient(client, apiURL, bitbucketHeaders(token)), project: project, slug: slug}
+}
+
+func (b *BitbucketServer) Name() string {
+	return "Bitbucket Server"
+}
+
+type bitbucketServerTag struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+func (t *bitbucketServerTag) release() *Release {
+	return &Release{ID: t.DisplayID, TagName: t.DisplayID, Name: t.DisplayID, Target: t.LatestCommit}
+}
+
+func (b *BitbucketServer) repoPath() string {
+	return "/projects/" + url.PathEscape(b.project) + "/repos/" + url.PathEscape(b.slug)
+}
+
+func (b *BitbucketServer) Create(ctx context.Context, release *Release) (*Release, error) {
+	tag, err := b.tag(ctx, release.TagName)
+	if isNotFound(err) {
+		body := map[string]string{
+			"name":       release.TagName,
+			"startPoint": release.Target,
+			"message":    "Version " + release.TagName,
+		}
+		tag = &bitbucketServerTag{}
+		err = b.api.do(ctx, http.MethodPost, b.repoPath()+"/tags", body, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.comment(ctx, tag.LatestCommit, release); err != nil {
+		return nil, err
+	}
+
+	created := tag.release()
+	created.Name, created.Body = release.Name, release.Body
+	return created, nil
+}
+
+func (b *BitbucketServer) Get(ctx context.Context, tag string) (*Release, error) {
+	found, err := b.tag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return found.release(), nil
+}
+
+// Update posts the new notes as another comment on the tagged commit.
+func (b *BitbucketServer) Update(ctx context.Context, release *Release) (*Release, error) {
+	tag, err := b.tag(ctx, release.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.comment(ctx, tag.LatestCommit, release); err != nil {
+		return nil, err
+	}
+
+	updated := tag.release()
+	updated.Name, updated.Body = release.Name, release.Body
+	return updated, nil
+}
+
+// Delete is not supported: the only release artifacts are the tag and the
+// commit comments, which are left for the user to remove.
+func (b *BitbucketServer) Delete(ctx context.Context, tag string) error {
+	return fmt.Errorf("o Bitbucket Server não tem releases para remover; apague a tag %s se necessário", tag)
+}
+
+func (b *BitbucketServer) tag(ctx context.Context, name string) (*bitbucketServerTag, error) {
+	var tag bitbucketServerTag
+	err := b.api.do(ctx, http.MethodGet, b.repoPath()+"/tags/"+url.PathEscape(name), nil, &tag)
+	if isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (b *BitbucketServer) comment(ctx context.Context, commit string, release *Release) error {
+	if release.Body == "" {
+		return nil
+	}
+	body := map[string]string{"text": commentText(release)}
+	return b.api.do(ctx, http.MethodPost, b.repoPath()+"/commits/"+commit+"/comments", body, nil)
+}
@@ -0,0 +1,133 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+func TestBitbucketCloudProvider(t *testing.T) {
+	var requests []string
+	var uploaded, comment string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /2.0/repositories/acme/widgets/refs/tags/v1.1.0":
+			w.WriteHeader(http.StatusNotFound)
+		case "GET /2.0/repositories/acme/widgets/refs/branches/main":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"target": map[string]string{"hash": testCommit}})
+		case "POST /2.0/repositories/acme/widgets/refs/tags":
+			data, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(data), testCommit) {
+				t.Errorf("A tag deveria apontar para o commit da branch: %s", data)
+			}
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"name": "v1.1.0", "target": map[string]string{"hash": testCommit}})
+		case "POST /2.0/repositories/acme/widgets/downloads":
+			file, header, err := r.FormFile("files")
+			if err != nil {
+				t.Fatalf("Upload sem arquivo: %v", err)
+			}
+			content, _ := io.ReadAll(file)
+			uploaded = header.Filename + ":" + string(content)
+			w.WriteHeader(http.StatusCreated)
+		case "POST /2.0/repositories/acme/widgets/commit/" + testCommit + "/comments":
+			data, _ := io.ReadAll(r.Body)
+			comment = string(data)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"id": 1})
+		case "DELETE /2.0/repositories/acme/widgets/downloads/release-notes-v1.1.0.md":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("bitbucket", server.Client(), server.URL+"/2.0", "acme/widgets", "user:app-password")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := provider.Get(ctx, "v1.1.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get de tag inexistente: esperava ErrNotFound, obteve %v", err)
+	}
+
+	created, err := provider.Create(ctx, &Release{TagName: "v1.1.0", Name: "Release v1.1.0", Body: "* novidade", Target: "main"})
+	if err != nil {
+		t.Fatalf("Create falhou: %v", err)
+	}
+	if created.Target != testCommit {
+		t.Errorf("Commit da release: esperava %s, obteve %s", testCommit, created.Target)
+	}
+
+	if uploaded != "release-notes-v1.1.0.md:* novidade" {
+		t.Errorf("Notas enviadas para Downloads incorretas: %q", uploaded)
+	}
+
+	if !strings.Contains(comment, `"raw":"## Release v1.1.0\n\n* novidade"`) {
+		t.Errorf("Comentário no commit incorreto: %s", comment)
+	}
+
+	if err := provider.Delete(ctx, "v1.1.0"); err != nil {
+		t.Errorf("Delete falhou: %v", err)
+	}
+}
+
+func TestBitbucketServerProvider(t *testing.T) {
+	var comment, auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		auth = r.Header.Get("Authorization")
+
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /rest/api/1.0/projects/PROJ/repos/widgets/tags/v2.0.0":
+			writeJSON(w, http.StatusOK, map[string]string{"displayId": "v2.0.0", "latestCommit": testCommit})
+		case "POST /rest/api/1.0/projects/PROJ/repos/widgets/commits/" + testCommit + "/comments":
+			data, _ := io.ReadAll(r.Body)
+			comment = string(data)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"id": 1})
+		default:
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("bitbucket-server", server.Client(), server.URL+"/rest/api/1.0", "scm/PROJ/widgets", "token")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// A tag já foi enviada com git: só o comentário é criado
+	created, err := provider.Create(context.Background(), &Release{TagName: "v2.0.0", Body: "* novidade", Target: "main"})
+	if err != nil {
+		t.Fatalf("Create falhou: %v", err)
+	}
+	if created.Target != testCommit {
+		t.Errorf("Commit da release: esperava %s, obteve %s", testCommit, created.Target)
+	}
+
+	if auth != "Bearer token" {
+		t.Errorf("Authorization: esperava 'Bearer token', obteve '%s'", auth)
+	}
+
+	if comment != `{"text":"* novidade"}` {
+		t.Errorf("Comentário no commit incorreto: %s", comment)
+	}
+
+	if err := provider.Delete(context.Background(), "v2.0.0"); err == nil {
+		t.Error("Delete deveria falhar no Bitbucket Server")
+	}
+}
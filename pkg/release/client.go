@@ -0,0 +1,298 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// requestTimeout bounds each attempt of a request.
+	requestTimeout = 30 * time.Second
+
+	// minUploadRate, in bytes per second, extends the timeout of large bodies
+	// such as release assets.
+	minUploadRate = 100 << 10
+
+	// maxAttempts is how many times a request is sent before giving up.
+	maxAttempts = 4
+
+	// baseDelay and maxDelay bound the exponential backoff between attempts.
+	baseDelay = time.Second
+	maxDelay  = 30 * time.Second
+
+	// maxRateLimitWait is the longest wait for a rate limit to reset; a longer
+	// one fails the request instead.
+	maxRateLimitWait = 2 * time.Minute
+)
+
+// sleep waits for d or until ctx is done. Tests replace it to run instantly.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// apiClient sends JSON requests to a provider API. Each attempt has its own
+// timeout, and failures caused by the network, 5xx responses or an exhausted
+// rate limit are retried with exponential backoff, honouring Retry-After and
+// the X-RateLimit-* and RateLimit-* headers.
+//
+// POST requests are never retried here, since a failed POST may still have
+// created something: they return a *retryableError for the caller to check
+// what exists before trying again.
+type apiClient struct {
+	http    *http.Client
+	baseURL string
+	headers http.Header
+
+	// rateLimitedUntil is when the API accepts requests again, after a
+	// response reported the rate limit exhausted.
+	rateLimitedUntil time.Time
+}
+
+func newAPIClient(client *http.Client, baseURL string, headers http.Header) *apiClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &apiClient{http: client, baseURL: strings.TrimSuffix(baseURL, "/"), headers: headers}
+}
+
+// retryableError is a transient failure that may succeed if retried.
+type retryableError struct {
+	err error
+
+	// delay is the wait asked by the server, if any.
+	delay time.Duration
+
+	// rateLimited failures wait for the rate limit to reset instead.
+	rateLimited bool
+}
+
+// wait returns how long to wait before the given retry, starting at 1.
+func (e *retryableError) wait(retry int) time.Duration {
+	switch {
+	case e.rateLimited:
+		return 0
+	case e.delay > 0:
+		return e.delay
+	}
+	return backoff(retry)
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// do sends in as the JSON body of a request to path and decodes the response
+// into out. Either may be nil. A non-2xx status returns an *APIError.
+func (c *apiClient) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	if in == nil {
+		return c.send(ctx, method, path, "", nil, out)
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados da release: %v", err)
+	}
+	return c.send(ctx, method, path, "application/json", data, out)
+}
+
+// send is like do but with a raw body of the given content type. path may
+// also be an absolute URL, such as an upload endpoint on another host.
+func (c *apiClient) send(ctx context.Context, method string, path string, contentType string, body []byte, out interface{}) error {
+	url := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		url = c.baseURL + path
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.waitRateLimit(ctx); err != nil {
+			return err
+		}
+
+		respBody, err := c.attempt(ctx, method, url, contentType, body)
+		if err == nil {
+			if out != nil && len(bytes.TrimSpace(respBody)) > 0 {
+				if err := json.Unmarshal(respBody, out); err != nil {
+					return fmt.Errorf("resposta inválida de %s: %v", url, err)
+				}
+			}
+			return nil
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || method == http.MethodPost || attempt == maxAttempts {
+			return err
+		}
+
+		if wait := retryable.wait(attempt); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return retryable.err
+			}
+		}
+	}
+}
+
+// attempt sends the request once and returns the response body of a 2xx
+// status. Transient failures are returned as a *retryableError.
+func (c *apiClient) attempt(ctx context.Context, method string, url string, contentType string, body []byte) ([]byte, error) {
+	timeout := requestTimeout + time.Duration(len(body)/minUploadRate)*time.Second
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(attemptCtx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %v", err)
+	}
+
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+
+		var respBody []byte
+		if respBody, err = io.ReadAll(resp.Body); err == nil {
+			return c.checkResponse(method, url, resp, respBody)
+		}
+	}
+
+	// The caller gave up: retrying would be pointless.
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("erro ao enviar requisição para %s: %v", url, ctx.Err())
+	}
+	if attemptCtx.Err() != nil {
+		err = fmt.Errorf("tempo esgotado após %s", timeout)
+	}
+	return nil, &retryableError{err: fmt.Errorf("erro ao enviar requisição para %s: %v", url, err)}
+}
+
+// checkResponse returns body for a 2xx status and an *APIError otherwise,
+// wrapped in a *retryableError for 5xx statuses and exhausted rate limits.
+func (c *apiClient) checkResponse(method string, url string, resp *http.Response, body []byte) ([]byte, error) {
+	reset, limited := rateLimitReset(resp.Header, time.Now())
+	if limited {
+		c.rateLimitedUntil = time.Now().Add(reset)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return body, nil
+	}
+
+	apiErr := &APIError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(body)}
+
+	switch {
+	case limited && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden):
+		// waitRateLimit waits for the reset before the next attempt.
+		return nil, &retryableError{err: apiErr, rateLimited: true}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &retryableError{err: apiErr, delay: retryAfter(resp.Header, 0)}
+	}
+	return nil, apiErr
+}
+
+// waitRateLimit waits until the API accepts requests again, failing when the
+// rate limit resets too far in the future.
+func (c *apiClient) waitRateLimit(ctx context.Context) error {
+	wait := time.Until(c.rateLimitedUntil)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxRateLimitWait {
+		return fmt.Errorf("limite de requisições da API esgotado até %s", c.rateLimitedUntil.Format(time.RFC3339))
+	}
+	return sleep(ctx, wait)
+}
+
+// backoff returns the exponential delay before the given retry, starting at 1.
+func backoff(retry int) time.Duration {
+	delay := baseDelay << (retry - 1)
+	if delay > maxDelay || delay <= 0 {
+		return maxDelay
+	}
+	return delay
+}
+
+// retryAfter returns the delay asked by the Retry-After header, in seconds or
+// as an HTTP date, or fallback when there is none.
+func retryAfter(header http.Header, fallback time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return fallback
+}
+
+// rateLimitReset reports whether header says the rate limit is exhausted and
+// how long until it resets. GitHub and Gitea send X-RateLimit-Remaining and
+// X-RateLimit-Reset as a Unix time; GitLab sends RateLimit-Remaining and
+// RateLimit-Reset, also a Unix time, while the IETF draft's RateLimit-Reset
+// is a number of seconds. Retry-After, when present, takes precedence.
+func rateLimitReset(header http.Header, now time.Time) (time.Duration, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if strings.TrimSpace(header.Get(prefix+"Remaining")) != "0" {
+			continue
+		}
+
+		if header.Get("Retry-After") != "" {
+			return retryAfter(header, 0), true
+		}
+
+		reset, err := strconv.ParseInt(strings.TrimSpace(header.Get(prefix+"Reset")), 10, 64)
+		if err != nil {
+			return backoff(1), true
+		}
+
+		// Values this large are Unix times rather than a number of seconds.
+		if reset > 1_000_000_000 {
+			if wait := time.Unix(reset, 0).Sub(now); wait > 0 {
+				return wait, true
+			}
+			return 0, true
+		}
+		return time.Duration(reset) * time.Second, true
+	}
+	return 0, false
+}
@@ -0,0 +1,221 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+// recordSleeps troca as esperas entre tentativas por um registro das durações
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+
+	original := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = original })
+
+	return &delays
+}
+
+// scriptedServer responde a cada requisição com a próxima resposta da lista
+func scriptedServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*apiClient, *int) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count >= len(responses) {
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		responses[count](w)
+		count++
+	}))
+	t.Cleanup(server.Close)
+
+	return newAPIClient(server.Client(), server.URL, http.Header{}), &count
+}
+
+func status(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}
+}
+
+func TestClientRetriesServerErrorsWithBackoff(t *testing.T) {
+	delays := recordSleeps(t)
+	client, count := scriptedServer(t, status(502), status(500), status(200))
+
+	var out map[string]bool
+	if err := client.do(context.Background(), http.MethodGet, "/releases", nil, &out); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if *count != 3 || !out["ok"] {
+		t.Errorf("Esperava 3 tentativas e a resposta final, obteve %d %v", *count, out)
+	}
+	if len(*delays) != 2 || (*delays)[0] != time.Second || (*delays)[1] != 2*time.Second {
+		t.Errorf("Esperava esperas de 1s e 2s, obteve %v", *delays)
+	}
+}
+
+func TestClientGivesUpAfterMaxAttempts(t *testing.T) {
+	recordSleeps(t)
+	client, count := scriptedServer(t, status(503), status(503), status(503), status(503))
+
+	err := client.do(context.Background(), http.MethodPut, "/releases/1", map[string]string{}, nil)
+	if !isStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("Esperava o erro 503 da última tentativa, obteve %v", err)
+	}
+	if *count != maxAttempts {
+		t.Errorf("Esperava %d tentativas, obteve %d", maxAttempts, *count)
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	delays := recordSleeps(t)
+	client, _ := scriptedServer(t, status(503, "Retry-After", "7"), status(200))
+
+	if err := client.do(context.Background(), http.MethodGet, "/releases", nil, nil); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(*delays) != 1 || (*delays)[0] != 7*time.Second {
+		t.Errorf("Esperava esperar os 7s do Retry-After, obteve %v", *delays)
+	}
+}
+
+func TestClientWaitsForRateLimitReset(t *testing.T) {
+	testCases := []struct {
+		name    string
+		limited func(w http.ResponseWriter)
+	}{
+		{"GitHub", status(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10))},
+		{"GitLab", status(429, "RateLimit-Remaining", "0", "RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10))},
+		{"IETF", status(429, "RateLimit-Remaining", "0", "RateLimit-Reset", "30")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delays := recordSleeps(t)
+			client, count := scriptedServer(t, tc.limited, status(200))
+
+			if err := client.do(context.Background(), http.MethodGet, "/releases", nil, nil); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			if *count != 2 || len(*delays) != 1 || (*delays)[0] < 25*time.Second || (*delays)[0] > 31*time.Second {
+				t.Errorf("Esperava esperar cerca de 30s pelo reset, obteve %v", *delays)
+			}
+		})
+	}
+}
+
+func TestClientRateLimitTooFarAway(t *testing.T) {
+	recordSleeps(t)
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	client, count := scriptedServer(t, status(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset))
+
+	err := client.do(context.Background(), http.MethodGet, "/releases", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "limite de requisições da API esgotado") {
+		t.Errorf("Esperava erro de limite esgotado, obteve %v", err)
+	}
+	if *count != 1 {
+		t.Errorf("Esperava uma única requisição, obteve %d", *count)
+	}
+}
+
+func TestClientDoesNotRetryPostOrClientErrors(t *testing.T) {
+	recordSleeps(t)
+
+	client, count := scriptedServer(t, status(502))
+	err := client.do(context.Background(), http.MethodPost, "/releases", map[string]string{}, nil)
+
+	var retryable *retryableError
+	if !errors.As(err, &retryable) || !isStatus(err, http.StatusBadGateway) || *count != 1 {
+		t.Errorf("Esperava um único POST com erro temporário, obteve %d %v", *count, err)
+	}
+
+	client, count = scriptedServer(t, status(422))
+	err = client.do(context.Background(), http.MethodPatch, "/releases/1", map[string]string{}, nil)
+	if !isStatus(err, http.StatusUnprocessableEntity) || errors.As(err, &retryable) || *count != 1 {
+		t.Errorf("Esperava um único PATCH com erro definitivo, obteve %d %v", *count, err)
+	}
+}
+
+func TestCreateReleaseRetryLooksUpByTag(t *testing.T) {
+	testCases := []struct {
+		name           string
+		createdOnError bool
+		requests       []string
+	}{
+		{"Created Despite Error", true, []string{
+			"GET /repos/acme/widgets/releases/tags/v1.0.0",
+			"GET /repos/acme/widgets/releases",
+			"POST /repos/acme/widgets/releases",
+			"GET /repos/acme/widgets/releases/tags/v1.0.0",
+		}},
+		{"Not Created", false, []string{
+			"GET /repos/acme/widgets/releases/tags/v1.0.0",
+			"GET /repos/acme/widgets/releases",
+			"POST /repos/acme/widgets/releases",
+			"GET /repos/acme/widgets/releases/tags/v1.0.0",
+			"GET /repos/acme/widgets/releases",
+			"POST /repos/acme/widgets/releases",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recordSleeps(t)
+
+			var created map[string]interface{}
+			posts := 0
+
+			forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+				switch r.Method + " " + r.URL.Path {
+				case "POST /repos/acme/widgets/releases":
+					posts++
+					if posts == 1 {
+						if tc.createdOnError {
+							created = body
+							created["id"] = 42
+						}
+						writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+						return
+					}
+					created = body
+					created["id"] = 42
+					writeJSON(w, http.StatusCreated, created)
+				case "GET /repos/acme/widgets/releases/tags/v1.0.0":
+					if created == nil {
+						writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+						return
+					}
+					writeJSON(w, http.StatusOK, created)
+				case "GET /repos/acme/widgets/releases":
+					writeJSON(w, http.StatusOK, []interface{}{})
+				}
+			})
+
+			if err := newTestReleaseManager(t, forge, config.NewConfig()).CreateRelease("v1.0.0"); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			if strings.Join(forge.requests, "\n") != strings.Join(tc.requests, "\n") {
+				t.Errorf("Esperava as requisições %v, obteve %v", tc.requests, forge.requests)
+			}
+		})
+	}
+}
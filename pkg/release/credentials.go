@@ -0,0 +1,270 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Credential is an API token and a description of where it was found.
+type Credential struct {
+	Token  string
+	Source string
+}
+
+// Masked returns the token with all but its first and last characters hidden,
+// for reporting which token is in use.
+func (c *Credential) Masked() string {
+	if len(c.Token) < 12 {
+		return strings.Repeat("*", len(c.Token))
+	}
+	return c.Token[:4] + strings.Repeat("*", 8) + c.Token[len(c.Token)-4:]
+}
+
+// CredentialChain finds the token of a provider at a host, trying in order:
+//
+//   - the per-host variable VERSION_MANAGER_TOKEN_<HOST>, e.g.
+//     VERSION_MANAGER_TOKEN_GITHUB_EXAMPLE_COM;
+//   - the provider's variable, e.g. GITHUB_TOKEN;
+//   - git credential fill for the host;
+//   - the machine entry of the host in ~/.netrc;
+//   - the token stored by gh (GitHub) or glab (GitLab) in their config files.
+type CredentialChain struct {
+	getenv  func(key string) string
+	homeDir string
+
+	// gitCredentialFill returns the username and password git has for host.
+	gitCredentialFill func(host string) (string, string, error)
+}
+
+func NewCredentialChain() *CredentialChain {
+	home, _ := os.UserHomeDir()
+	return &CredentialChain{getenv: os.Getenv, homeDir: home, gitCredentialFill: gitCredentialFill}
+}
+
+// HostEnvVar returns the per-host token variable of host.
+func HostEnvVar(host string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, host)
+	return "VERSION_MANAGER_TOKEN_" + strings.ToUpper(name)
+}
+
+// Resolve returns the first credential found for provider at host, whose API
+// is at apiHost, e.g. "api.github.com" for "github.com".
+func (c *CredentialChain) Resolve(provider string, host string, apiHost string) (*Credential, error) {
+	hosts := []string{host}
+	if apiHost != "" && !strings.EqualFold(apiHost, host) {
+		hosts = append(hosts, apiHost)
+	}
+
+	if token := c.getenv(HostEnvVar(host)); token != "" {
+		return &Credential{Token: token, Source: "variável " + HostEnvVar(host)}, nil
+	}
+
+	for _, envVar := range providerEnvVars(provider) {
+		if token := c.getenv(envVar); token != "" {
+			return &Credential{Token: token, Source: "variável " + envVar}, nil
+		}
+	}
+
+	if username, password, err := c.gitCredentialFill(host); err == nil && password != "" {
+		return &Credential{Token: credentialToken(provider, username, password), Source: "git credential (" + host + ")"}, nil
+	}
+
+	netrcPath := c.netrcPath()
+	if machine, login, password, ok := netrcMachine(netrcPath, hosts); ok {
+		return &Credential{Token: credentialToken(provider, login, password), Source: netrcPath + " (" + machine + ")"}, nil
+	}
+
+	switch provider {
+	case "github":
+		path := c.configPath("GH_CONFIG_DIR", "gh", "hosts.yml")
+		if token := ghToken(path, host); token != "" {
+			return &Credential{Token: token, Source: path + " (gh)"}, nil
+		}
+	case "gitlab":
+		path := c.configPath("GLAB_CONFIG_DIR", "glab-cli", "config.yml")
+		if token := glabToken(path, host); token != "" {
+			return &Credential{Token: token, Source: path + " (glab)"}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("token de acesso para %s não encontrado. Configure a variável %s ou %s, um helper do git credential, o ~/.netrc%s",
+		host, HostEnvVar(host), tokenEnvVars[provider], loginHint(provider))
+}
+
+// providerEnvVars returns the variables holding the token of provider.
+func providerEnvVars(provider string) []string {
+	vars := []string{tokenEnvVars[provider]}
+	if provider == "forgejo" {
+		// Forgejo instances are often set up with the Gitea tooling.
+		vars = append(vars, tokenEnvVars["gitea"])
+	}
+	return vars
+}
+
+func loginHint(provider string) string {
+	switch provider {
+	case "github":
+		return " ou faça login com \"gh auth login\""
+	case "gitlab":
+		return " ou faça login com \"glab auth login\""
+	}
+	return ""
+}
+
+// credentialToken turns a username and password into a token. Bitbucket
+// app passwords need the username for basic auth, unless the password is an
+// access token, which git stores under "x-token-auth".
+func credentialToken(provider string, username string, password string) string {
+	if strings.HasPrefix(provider, "bitbucket") && username != "" && username != "x-token-auth" {
+		return username + ":" + password
+	}
+	return password
+}
+
+// gitCredentialFill asks the configured git credential helpers for host,
+// without letting git prompt for a password.
+func gitCredentialFill(host string) (string, string, error) {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=true", "SSH_ASKPASS=true", "GCM_INTERACTIVE=never")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", err
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			values[key] = value
+		}
+	}
+	return values["username"], values["password"], nil
+}
+
+func (c *CredentialChain) netrcPath() string {
+	if path := c.getenv("NETRC"); path != "" {
+		return path
+	}
+	return filepath.Join(c.homeDir, ".netrc")
+}
+
+// netrcMachine returns the login and password of the first of hosts with an
+// entry in the netrc file at path, falling back to its default entry.
+func netrcMachine(path string, hosts []string) (string, string, string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", "", false
+	}
+
+	type entry struct{ login, password string }
+	machines := map[string]*entry{}
+	var current *entry
+
+	fields := strings.Fields(string(data))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine", "default":
+			name := "default"
+			if fields[i] == "machine" && i+1 < len(fields) {
+				i++
+				name = strings.ToLower(fields[i])
+			}
+			current = nil
+			if machines[name] == nil {
+				current = &entry{}
+				machines[name] = current
+			}
+		case "login", "password":
+			if i+1 < len(fields) {
+				i++
+				if current != nil && fields[i-1] == "login" {
+					current.login = fields[i]
+				} else if current != nil {
+					current.password = fields[i]
+				}
+			}
+		case "macdef":
+			// Macros run until a blank line, which Fields cannot see; netrc
+			// files with tokens do not use them.
+			current = nil
+		}
+	}
+
+	for _, host := range append(hosts, "default") {
+		if e := machines[strings.ToLower(host)]; e != nil && e.password != "" {
+			return host, e.login, e.password, true
+		}
+	}
+	return "", "", "", false
+}
+
+// configPath returns file in the config directory of a CLI: $envVar, or else
+// $XDG_CONFIG_HOME/dir or ~/.config/dir.
+func (c *CredentialChain) configPath(envVar string, dir string, file string) string {
+	if configDir := c.getenv(envVar); configDir != "" {
+		return filepath.Join(configDir, file)
+	}
+	if xdg := c.getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, dir, file)
+	}
+	return filepath.Join(c.homeDir, ".config", dir, file)
+}
+
+// ghToken reads the oauth_token of host from gh's hosts.yml. Recent gh
+// versions keep it in the system keyring instead, where it is not found.
+func ghToken(path string, host string) string {
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if !readYAML(path, &hosts) {
+		return ""
+	}
+
+	for name, h := range hosts {
+		if strings.EqualFold(name, host) {
+			return h.OAuthToken
+		}
+	}
+	return ""
+}
+
+// glabToken reads the token of host from glab's config.yml.
+func glabToken(path string, host string) string {
+	var cfg struct {
+		Hosts map[string]struct {
+			Token string `yaml:"token"`
+		} `yaml:"hosts"`
+	}
+	if !readYAML(path, &cfg) {
+		return ""
+	}
+
+	for name, h := range cfg.Hosts {
+		if strings.EqualFold(name, host) {
+			return h.Token
+		}
+	}
+	return ""
+}
+
+func readYAML(path string, out interface{}) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return yaml.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,127 @@
+package release
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestChain cria uma CredentialChain isolada do ambiente, com home em um
+// diretório temporário e o git credential respondendo com fill
+func newTestChain(t *testing.T, env map[string]string, fill func(host string) (string, string, error)) (*CredentialChain, string) {
+	home := t.TempDir()
+	if fill == nil {
+		fill = func(host string) (string, string, error) { return "", "", errors.New("sem helper") }
+	}
+	return &CredentialChain{
+		getenv:            func(key string) string { return env[key] },
+		homeDir:           home,
+		gitCredentialFill: fill,
+	}, home
+}
+
+func writeHomeFile(t *testing.T, home string, name string, content string) {
+	path := filepath.Join(home, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCredentialChainOrder(t *testing.T) {
+	env := map[string]string{
+		"VERSION_MANAGER_TOKEN_GITHUB_EXAMPLE_COM": "host-token-123456",
+		"GITHUB_TOKEN": "provider-token-123456",
+	}
+	fill := func(host string) (string, string, error) { return "octocat", "helper-token-123456", nil }
+
+	chain, home := newTestChain(t, env, fill)
+	writeHomeFile(t, home, ".netrc", "machine github.example.com login octocat password netrc-token-123456\n")
+	writeHomeFile(t, home, ".config/gh/hosts.yml", "github.example.com:\n    oauth_token: gh-token-123456\n")
+
+	expected := []string{"variável VERSION_MANAGER_TOKEN_GITHUB_EXAMPLE_COM", "variável GITHUB_TOKEN", "git credential", ".netrc", "(gh)"}
+
+	for _, source := range expected {
+		credential, err := chain.Resolve("github", "github.example.com", "github.example.com")
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !strings.Contains(credential.Source, source) {
+			t.Fatalf("Esperava a origem %q, obteve %q", source, credential.Source)
+		}
+
+		// Remove a origem vencedora para a próxima volta
+		switch {
+		case len(env) > 0 && strings.HasPrefix(source, "variável "):
+			delete(env, strings.TrimPrefix(source, "variável "))
+		case source == "git credential":
+			chain.gitCredentialFill = func(host string) (string, string, error) { return "", "", errors.New("sem helper") }
+		case source == ".netrc":
+			os.Remove(filepath.Join(home, ".netrc"))
+		}
+	}
+}
+
+func TestCredentialChainNetrcAndBitbucket(t *testing.T) {
+	chain, home := newTestChain(t, map[string]string{}, nil)
+	writeHomeFile(t, home, ".netrc", `
+machine api.bitbucket.org
+  login jdoe
+  password app-password
+default login anonymous password default-secret
+`)
+
+	credential, err := chain.Resolve("bitbucket", "bitbucket.org", "api.bitbucket.org")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if credential.Token != "jdoe:app-password" {
+		t.Errorf("Esperava usuário e app password do .netrc, obteve %q", credential.Token)
+	}
+
+	credential, err = chain.Resolve("gitea", "gitea.example.com", "gitea.example.com")
+	if err != nil || credential.Token != "default-secret" {
+		t.Errorf("Esperava a entrada default do .netrc, obteve %v %v", credential, err)
+	}
+}
+
+func TestCredentialChainGlab(t *testing.T) {
+	chain, home := newTestChain(t, map[string]string{"XDG_CONFIG_HOME": ""}, nil)
+	writeHomeFile(t, home, ".config/glab-cli/config.yml", `
+git_protocol: ssh
+hosts:
+    gitlab.example.com:
+        token: glpat-abcdefghijkl
+        api_host: gitlab.example.com
+`)
+
+	credential, err := chain.Resolve("gitlab", "gitlab.example.com", "gitlab.example.com")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if credential.Token != "glpat-abcdefghijkl" || !strings.HasSuffix(credential.Source, "(glab)") {
+		t.Errorf("Esperava o token do glab, obteve %+v", credential)
+	}
+	if masked := credential.Masked(); masked != "glpa********ijkl" {
+		t.Errorf("Token mascarado inesperado: %s", masked)
+	}
+}
+
+func TestCredentialChainNotFound(t *testing.T) {
+	chain, _ := newTestChain(t, map[string]string{}, nil)
+
+	_, err := chain.Resolve("github", "github.com", "api.github.com")
+	if err == nil || !strings.Contains(err.Error(), "VERSION_MANAGER_TOKEN_GITHUB_COM") || !strings.Contains(err.Error(), "gh auth login") {
+		t.Errorf("Esperava erro listando as opções, obteve %v", err)
+	}
+}
+
+func TestCredentialMaskedShortToken(t *testing.T) {
+	if masked := (&Credential{Token: "abc"}).Masked(); masked != "***" {
+		t.Errorf("Tokens curtos deveriam ser totalmente mascarados, obteve %s", masked)
+	}
+}
@@ -0,0 +1,48 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RecordingTransport is an http.RoundTripper that sends nothing: it passes a
+// description of each request to record and answers with an empty success.
+type RecordingTransport struct {
+	record func(step string)
+}
+
+func NewRecordingTransport(record func(step string)) *RecordingTransport {
+	return &RecordingTransport{record: record}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	step := fmt.Sprintf("%s %s", req.Method, req.URL.String())
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		switch contentType := req.Header.Get("Content-Type"); {
+		case len(body) == 0:
+		case contentType == "" || contentType == "application/json":
+			step += "\n    " + string(body)
+		default:
+			// Uploaded files are summarized rather than dumped.
+			step += fmt.Sprintf("\n    <%d bytes, %s>", len(body), contentType)
+		}
+	}
+
+	t.record(step)
+
+	return &http.Response{
+		StatusCode: http.StatusCreated,
+		Status:     "201 Created",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
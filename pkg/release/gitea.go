@@ -0,0 +1,53 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Gitea manages releases on Gitea, whose release API mirrors GitHub's.
+type Gitea struct {
+	githubAPI
+}
+
+// NewGitea returns the Gitea provider for repo using the API at apiURL, such
+// as https://gitea.example.com/api/v1.
+func NewGitea(client *http.Client, apiURL string, repo string, token string) *Gitea {
+	return newGitea("Gitea", client, apiURL, repo, token)
+}
+
+// NewForgejo returns the provider for Forgejo, a Gitea fork with the same API.
+func NewForgejo(client *http.Client, apiURL string, repo string, token string) *Gitea {
+	return newGitea("Forgejo", client, apiURL, repo, token)
+}
+
+func newGitea(name string, client *http.Client, apiURL string, repo string, token string) *Gitea {
+	headers := http.Header{}
+	headers.Set("Accept", "application/json")
+	headers.Set("Authorization", "token "+token)
+
+	return &Gitea{githubAPI{name: name, api: newAPIClient(client, apiURL, headers), repo: repo}}
+}
+
+// UploadAsset attaches asset to release.
+func (g *Gitea) UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error) {
+	data, err := asset.read()
+	if err != nil {
+		return "", err
+	}
+
+	contentType, body, err := multipartFile("attachment", asset.Name, data)
+	if err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+	endpoint := g.releasesPath() + "/" + release.ID + "/assets?name=" + url.QueryEscape(asset.Name)
+	if err := g.api.send(ctx, http.MethodPost, endpoint, contentType, body, &uploaded); err != nil {
+		return "", err
+	}
+	return uploaded.BrowserDownloadURL, nil
+}
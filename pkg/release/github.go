@@ -0,0 +1,300 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type GitHubReleaseRequest struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+	MakeLatest      string `json:"make_latest,omitempty"`
+}
+
+type githubRelease struct {
+	ID              int64  `json:"id"`
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+	HTMLURL         string `json:"html_url"`
+	UploadURL       string `json:"upload_url"`
+}
+
+func (r *githubRelease) release() *Release {
+	return &Release{
+		ID:         strconv.FormatInt(r.ID, 10),
+		TagName:    r.TagName,
+		Name:       r.Name,
+		Body:       r.Body,
+		Target:     r.TargetCommitish,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		URL:        r.HTMLURL,
+		uploadURL:  r.UploadURL,
+	}
+}
+
+// githubAPI implements the release endpoints shared by GitHub and Gitea,
+// whose API mirrors GitHub's.
+type githubAPI struct {
+	name string
+	api  *apiClient
+	repo string
+}
+
+func (g *githubAPI) Name() string {
+	return g.name
+}
+
+func (g *githubAPI) releasesPath() string {
+	return "/repos/" + g.repo + "/releases"
+}
+
+func (g *githubAPI) Create(ctx context.Context, release *Release) (*Release, error) {
+	var created githubRelease
+	if err := g.api.do(ctx, http.MethodPost, g.releasesPath(), githubRequest(release), &created); err != nil {
+		return nil, err
+	}
+	return created.release(), nil
+}
+
+func (g *githubAPI) Get(ctx context.Context, tag string) (*Release, error) {
+	var found githubRelease
+	err := g.api.do(ctx, http.MethodGet, g.releasesPath()+"/tags/"+url.PathEscape(tag), nil, &found)
+	if err == nil {
+		return found.release(), nil
+	}
+	if !isStatus(err, http.StatusNotFound) {
+		return nil, err
+	}
+
+	// Drafts have no tag yet, so they are only found in the release list.
+	var releases []githubRelease
+	if err := g.api.do(ctx, http.MethodGet, g.releasesPath()+"?per_page=100", nil, &releases); err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if r.TagName == tag {
+			return r.release(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", tag, ErrNotFound)
+}
+
+func (g *githubAPI) Update(ctx context.Context, release *Release) (*Release, error) {
+	id := release.ID
+	if id == "" {
+		existing, err := g.Get(ctx, release.TagName)
+		if err != nil {
+			return nil, err
+		}
+		id = existing.ID
+	}
+
+	var updated githubRelease
+	if err := g.api.do(ctx, http.MethodPatch, g.releasesPath()+"/"+id, githubRequest(release), &updated); err != nil {
+		return nil, err
+	}
+	return updated.release(), nil
+}
+
+func (g *githubAPI) Delete(ctx context.Context, tag string) error {
+	existing, err := g.Get(ctx, tag)
+	if err != nil {
+		return err
+	}
+	return g.api.do(ctx, http.MethodDelete, g.releasesPath()+"/"+existing.ID, nil, nil)
+}
+
+func githubRequest(release *Release) *GitHubReleaseRequest {
+	return &GitHubReleaseRequest{
+		TagName:         release.TagName,
+		TargetCommitish: release.Target,
+		Name:            release.Name,
+		Body:            release.Body,
+		Draft:           release.Draft,
+		Prerelease:      release.Prerelease,
+		MakeLatest:      release.MakeLatest,
+	}
+}
+
+// GitHub manages releases on github.com and GitHub Enterprise Server.
+type GitHub struct {
+	githubAPI
+}
+
+// NewGitHub returns the GitHub provider for repo, e.g. "acme/widgets", using
+// the API at apiURL, such as https://api.github.com.
+func NewGitHub(client *http.Client, apiURL string, repo string, token string) *GitHub {
+	headers := http.Header{}
+	headers.Set("Accept", "application/vnd.github.v3+json")
+	headers.Set("Authorization", "token "+token)
+
+	return &GitHub{githubAPI{name: "GitHub", api: newAPIClient(client, apiURL, headers), repo: repo}}
+}
+
+// UploadAsset sends asset to the uploads endpoint of release.
+func (g *GitHub) UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error) {
+	data, err := asset.read()
+	if err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+	endpoint := g.uploadURL(release) + "?name=" + url.QueryEscape(asset.Name)
+	if err := g.api.send(ctx, http.MethodPost, endpoint, asset.contentType(), data, &uploaded); err != nil {
+		return "", err
+	}
+	return uploaded.BrowserDownloadURL, nil
+}
+
+// uploadURL returns the assets endpoint of release: the upload_url returned
+// by the API without its URI template, or else the one derived from the API
+// URL, on uploads.github.com or at /api/uploads on GitHub Enterprise.
+func (g *GitHub) uploadURL(release *Release) string {
+	if release.uploadURL != "" {
+		if i := strings.Index(release.uploadURL, "{"); i != -1 {
+			return release.uploadURL[:i]
+		}
+		return release.uploadURL
+	}
+
+	base := g.api.baseURL
+	if base == "https://api.github.com" {
+		base = "https://uploads.github.com"
+	} else {
+		base = strings.TrimSuffix(base, "/api/v3") + "/api/uploads"
+	}
+	return base + g.releasesPath() + "/" + release.ID + "/assets"
+}
+
+type githubPullRequest struct {
+	Number         int    `json:"number"`
+	NodeID         string `json:"node_id"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	MergedAt       string `json:"merged_at"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p *githubPullRequest) pullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number: p.Number,
+		Source: p.Head.Ref,
+		Target: p.Base.Ref,
+		Title:  p.Title,
+		Body:   p.Body,
+		URL:    p.HTMLURL,
+		State:  p.State,
+		nodeID: p.NodeID,
+	}
+	// The list endpoint has no "merged" field, only merged_at.
+	if p.Merged || p.MergedAt != "" {
+		pr.State = PullRequestMerged
+		pr.MergeCommit = p.MergeCommitSHA
+	}
+	return pr
+}
+
+func (g *GitHub) pullsPath() string {
+	return "/repos/" + g.repo + "/pulls"
+}
+
+// OpenPullRequest opens pr. GitHub refuses a second pull request between the
+// same branches, so on a validation error the open one is looked up.
+func (g *GitHub) OpenPullRequest(ctx context.Context, pr *PullRequest) (*PullRequest, error) {
+	body := map[string]string{"title": pr.Title, "head": pr.Source, "base": pr.Target, "body": pr.Body}
+
+	var created githubPullRequest
+	err := g.api.do(ctx, http.MethodPost, g.pullsPath(), body, &created)
+	if err == nil {
+		return created.pullRequest(), nil
+	}
+	if !isStatus(err, http.StatusUnprocessableEntity) {
+		return nil, err
+	}
+
+	owner, _, _ := strings.Cut(g.repo, "/")
+	query := url.Values{"state": {"open"}, "head": {owner + ":" + pr.Source}, "base": {pr.Target}}
+
+	var open []githubPullRequest
+	if listErr := g.api.do(ctx, http.MethodGet, g.pullsPath()+"?"+query.Encode(), nil, &open); listErr != nil || len(open) == 0 {
+		return nil, err
+	}
+	return open[0].pullRequest(), nil
+}
+
+func (g *GitHub) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var found githubPullRequest
+	if err := g.api.do(ctx, http.MethodGet, g.pullsPath()+"/"+strconv.Itoa(number), nil, &found); err != nil {
+		return nil, err
+	}
+	return found.pullRequest(), nil
+}
+
+// EnableAutoMerge enables auto-merge, which only the GraphQL API offers. It
+// fails when auto-merge is not allowed in the repository settings.
+func (g *GitHub) EnableAutoMerge(ctx context.Context, pr *PullRequest, method string) error {
+	request := map[string]interface{}{
+		"query": `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`,
+		"variables": map[string]string{"id": pr.nodeID, "method": strings.ToUpper(method)},
+	}
+
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := g.api.do(ctx, http.MethodPost, g.graphqlURL(), request, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("falha ao habilitar o auto-merge: %s", response.Errors[0].Message)
+	}
+	return nil
+}
+
+// graphqlURL returns the GraphQL endpoint: api.github.com/graphql, or
+// /api/graphql on GitHub Enterprise.
+func (g *GitHub) graphqlURL() string {
+	base := g.api.baseURL
+	if strings.HasSuffix(base, "/api/v3") {
+		return strings.TrimSuffix(base, "/v3") + "/graphql"
+	}
+	return base + "/graphql"
+}
+
+// DeleteBranch deletes the ref of branch. A protected branch is only deleted
+// when its protection allows deletions or the token can bypass it.
+func (g *GitHub) DeleteBranch(ctx context.Context, branch string) error {
+	segments := strings.Split(branch, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return g.api.do(ctx, http.MethodDelete, "/repos/"+g.repo+"/git/refs/heads/"+strings.Join(segments, "/"), nil, nil)
+}
@@ -0,0 +1,249 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GitLabReleaseRequest struct {
+	Name        string     `json:"name"`
+	TagName     string     `json:"tag_name,omitempty"`
+	Description string     `json:"description"`
+	Ref         string     `json:"ref,omitempty"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"`
+}
+
+type gitlabRelease struct {
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	Description string    `json:"description"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+func (r *gitlabRelease) release() *Release {
+	return &Release{
+		// GitLab identifies releases by their tag.
+		ID:         r.TagName,
+		TagName:    r.TagName,
+		Name:       r.Name,
+		Body:       r.Description,
+		ReleasedAt: r.ReleasedAt,
+		URL:        r.Links.Self,
+	}
+}
+
+// GitLab manages releases on gitlab.com and self-managed GitLab. GitLab has
+// no drafts nor a prerelease flag, so those fields are ignored; a ReleasedAt
+// in the future is the closest to a draft.
+type GitLab struct {
+	api     *apiClient
+	project string
+
+	// packageName names the generic package holding the release assets.
+	packageName string
+}
+
+// NewGitLab returns the GitLab provider for the project at path, e.g.
+// "group/sub/widgets", using the API at apiURL, such as
+// https://gitlab.com/api/v4.
+func NewGitLab(client *http.Client, apiURL string, path string, token string) *GitLab {
+	headers := http.Header{}
+	headers.Set("PRIVATE-TOKEN", token)
+
+	return &GitLab{
+		api:         newAPIClient(client, apiURL, headers),
+		project:     url.PathEscape(path),
+		packageName: path[strings.LastIndex(path, "/")+1:],
+	}
+}
+
+func (g *GitLab) Name() string {
+	return "GitLab"
+}
+
+func (g *GitLab) releasesPath() string {
+	return "/projects/" + g.project + "/releases"
+}
+
+func (g *GitLab) Create(ctx context.Context, release *Release) (*Release, error) {
+	body := &GitLabReleaseRequest{
+		Name:        release.Name,
+		TagName:     release.TagName,
+		Description: release.Body,
+		Ref:         release.Target,
+		ReleasedAt:  releasedAt(release),
+	}
+
+	var created gitlabRelease
+	if err := g.api.do(ctx, http.MethodPost, g.releasesPath(), body, &created); err != nil {
+		return nil, err
+	}
+	return created.release(), nil
+}
+
+func (g *GitLab) Get(ctx context.Context, tag string) (*Release, error) {
+	var found gitlabRelease
+	err := g.api.do(ctx, http.MethodGet, g.releasesPath()+"/"+url.PathEscape(tag), nil, &found)
+	if isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("%s: %w", tag, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found.release(), nil
+}
+
+func (g *GitLab) Update(ctx context.Context, release *Release) (*Release, error) {
+	body := &GitLabReleaseRequest{
+		Name:        release.Name,
+		Description: release.Body,
+		ReleasedAt:  releasedAt(release),
+	}
+
+	var updated gitlabRelease
+	if err := g.api.do(ctx, http.MethodPut, g.releasesPath()+"/"+url.PathEscape(release.TagName), body, &updated); err != nil {
+		return nil, err
+	}
+	return updated.release(), nil
+}
+
+func releasedAt(release *Release) *time.Time {
+	if release.ReleasedAt.IsZero() {
+		return nil
+	}
+	return &release.ReleasedAt
+}
+
+func (g *GitLab) Delete(ctx context.Context, tag string) error {
+	err := g.api.do(ctx, http.MethodDelete, g.releasesPath()+"/"+url.PathEscape(tag), nil, nil)
+	if isStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("%s: %w", tag, ErrNotFound)
+	}
+	return err
+}
+
+// UploadAsset publishes asset to the project's generic package registry, in
+// a version named after the release tag, and links it from the release.
+func (g *GitLab) UploadAsset(ctx context.Context, release *Release, asset Asset) (string, error) {
+	data, err := asset.read()
+	if err != nil {
+		return "", err
+	}
+
+	// Package versions cannot contain slashes, as monorepo tags do.
+	packageVersion := strings.ReplaceAll(release.TagName, "/", "-")
+	fileURL := g.api.baseURL + "/projects/" + g.project + "/packages/generic/" +
+		url.PathEscape(g.packageName) + "/" + url.PathEscape(packageVersion) + "/" + url.PathEscape(asset.Name)
+
+	if err := g.api.send(ctx, http.MethodPut, fileURL, "application/octet-stream", data, nil); err != nil {
+		return "", err
+	}
+
+	link := map[string]string{"name": asset.Name, "url": fileURL, "link_type": "package"}
+	if err := g.api.do(ctx, http.MethodPost, g.releasesPath()+"/"+url.PathEscape(release.TagName)+"/assets/links", link, nil); err != nil {
+		return "", err
+	}
+	return fileURL, nil
+}
+
+type gitlabMergeRequest struct {
+	IID             int    `json:"iid"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	WebURL          string `json:"web_url"`
+	State           string `json:"state"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+}
+
+func (m *gitlabMergeRequest) pullRequest() *PullRequest {
+	pr := &PullRequest{
+		Number:      m.IID,
+		Source:      m.SourceBranch,
+		Target:      m.TargetBranch,
+		Title:       m.Title,
+		Body:        m.Description,
+		URL:         m.WebURL,
+		State:       PullRequestClosed,
+		MergeCommit: m.MergeCommitSHA,
+	}
+
+	switch m.State {
+	case "opened", "locked":
+		pr.State = PullRequestOpen
+	case "merged":
+		pr.State = PullRequestMerged
+	}
+	// A squashed merge without a merge commit leaves the squash commit on
+	// the target.
+	if pr.MergeCommit == "" {
+		pr.MergeCommit = m.SquashCommitSHA
+	}
+	return pr
+}
+
+func (g *GitLab) mergeRequestsPath() string {
+	return "/projects/" + g.project + "/merge_requests"
+}
+
+// OpenPullRequest opens a merge request. GitLab answers 409 when one is
+// already open between the same branches, which is then returned.
+func (g *GitLab) OpenPullRequest(ctx context.Context, pr *PullRequest) (*PullRequest, error) {
+	body := map[string]string{
+		"source_branch": pr.Source,
+		"target_branch": pr.Target,
+		"title":         pr.Title,
+		"description":   pr.Body,
+	}
+
+	var created gitlabMergeRequest
+	err := g.api.do(ctx, http.MethodPost, g.mergeRequestsPath(), body, &created)
+	if err == nil {
+		return created.pullRequest(), nil
+	}
+	if !isStatus(err, http.StatusConflict) {
+		return nil, err
+	}
+
+	query := url.Values{"state": {"opened"}, "source_branch": {pr.Source}, "target_branch": {pr.Target}}
+
+	var open []gitlabMergeRequest
+	if listErr := g.api.do(ctx, http.MethodGet, g.mergeRequestsPath()+"?"+query.Encode(), nil, &open); listErr != nil || len(open) == 0 {
+		return nil, err
+	}
+	return open[0].pullRequest(), nil
+}
+
+func (g *GitLab) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var found gitlabMergeRequest
+	if err := g.api.do(ctx, http.MethodGet, g.mergeRequestsPath()+"/"+strconv.Itoa(number), nil, &found); err != nil {
+		return nil, err
+	}
+	return found.pullRequest(), nil
+}
+
+// EnableAutoMerge sets the merge request to merge when its pipeline succeeds.
+// GitLab has no rebase method here; the project's merge method applies.
+func (g *GitLab) EnableAutoMerge(ctx context.Context, pr *PullRequest, method string) error {
+	body := map[string]bool{
+		"merge_when_pipeline_succeeds": true,
+		"squash":                       method == MergeMethodSquash,
+	}
+	return g.api.do(ctx, http.MethodPut, g.mergeRequestsPath()+"/"+strconv.Itoa(pr.Number)+"/merge", body, nil)
+}
+
+// DeleteBranch deletes branch. Unlike a push, the API lets maintainers delete
+// protected branches.
+func (g *GitLab) DeleteBranch(ctx context.Context, branch string) error {
+	return g.api.do(ctx, http.MethodDelete, "/projects/"+g.project+"/repository/branches/"+url.PathEscape(branch), nil, nil)
+}
@@ -0,0 +1,104 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Release is a forge release, identified by the tag it publishes.
+type Release struct {
+	// ID is the provider's identifier, filled in by Get, Create and Update.
+	ID string
+
+	TagName    string
+	Name       string
+	Body       string
+	Target     string
+	Draft      bool
+	Prerelease bool
+
+	// MakeLatest is GitHub's make_latest: "true", "false" or "legacy". Empty
+	// leaves the choice to GitHub.
+	MakeLatest string
+
+	// ReleasedAt is GitLab's released_at; a future date makes it an upcoming
+	// release. The zero time leaves it to GitLab.
+	ReleasedAt time.Time
+
+	// URL is the web page of the release, when the provider returns one.
+	URL string
+
+	// uploadURL is where GitHub accepts the release's assets.
+	uploadURL string
+}
+
+// Provider creates, reads, updates and deletes the releases of one repository
+// on a forge.
+type Provider interface {
+	// Name is the provider's display name, e.g. "GitHub".
+	Name() string
+
+	Create(ctx context.Context, release *Release) (*Release, error)
+
+	// Get returns the release of tag, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, tag string) (*Release, error)
+
+	// Update replaces the title, notes and flags of the release of
+	// release.TagName.
+	Update(ctx context.Context, release *Release) (*Release, error)
+
+	Delete(ctx context.Context, tag string) error
+}
+
+// ErrNotFound is returned by Provider.Get when the tag has no release.
+var ErrNotFound = errors.New("release não encontrada")
+
+// APIError is an unexpected HTTP status returned by a provider API.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: código %d: %s", e.Method, e.URL, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Providers lists the names accepted by NewProvider.
+var Providers = []string{"github", "gitlab", "gitea", "forgejo", "bitbucket", "bitbucket-server"}
+
+// NewProvider returns the provider called name for the repository at
+// repoPath, e.g. "acme/widgets", talking to the API at apiURL.
+func NewProvider(name string, client *http.Client, apiURL string, repoPath string, token string) (Provider, error) {
+	switch name {
+	case "github":
+		return NewGitHub(client, apiURL, repoPath, token), nil
+	case "gitlab":
+		return NewGitLab(client, apiURL, repoPath, token), nil
+	case "gitea":
+		return NewGitea(client, apiURL, repoPath, token), nil
+	case "forgejo":
+		return NewForgejo(client, apiURL, repoPath, token), nil
+	case "bitbucket":
+		return NewBitbucketCloud(client, apiURL, repoPath, token), nil
+	case "bitbucket-server":
+		return NewBitbucketServer(client, apiURL, repoPath, token), nil
+	default:
+		return nil, fmt.Errorf("tipo de repositório não suportado: %s", name)
+	}
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// isStatus reports whether err is an *APIError with the given status code.
+func isStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
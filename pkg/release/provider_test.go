@@ -0,0 +1,239 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+// fakeForge é um servidor httptest que guarda as releases em memória e
+// registra as requisições recebidas
+type fakeForge struct {
+	t        *testing.T
+	server   *httptest.Server
+	requests []string
+	headers  []http.Header
+}
+
+func newFakeForge(t *testing.T, handler func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{})) *fakeForge {
+	f := &fakeForge{t: t}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests = append(f.requests, r.Method+" "+r.URL.EscapedPath())
+		f.headers = append(f.headers, r.Header.Clone())
+
+		body := map[string]interface{}{}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("Corpo inválido: %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		handler(f, w, r, body)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func TestGitHubProvider(t *testing.T) {
+	releases := map[string]map[string]interface{}{}
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/acme/widgets/releases":
+			body["id"] = 42
+			body["html_url"] = "https://github.example.com/acme/widgets/releases/" + body["tag_name"].(string)
+			releases["42"] = body
+			writeJSON(w, http.StatusCreated, body)
+		case "GET /repos/acme/widgets/releases/tags/v1.0.0":
+			// Rascunhos não são encontrados pela tag
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		case "GET /repos/acme/widgets/releases":
+			list := []map[string]interface{}{}
+			for _, release := range releases {
+				list = append(list, release)
+			}
+			writeJSON(w, http.StatusOK, list)
+		case "PATCH /repos/acme/widgets/releases/42":
+			for key, value := range body {
+				releases["42"][key] = value
+			}
+			writeJSON(w, http.StatusOK, releases["42"])
+		case "DELETE /repos/acme/widgets/releases/42":
+			delete(releases, "42")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	})
+
+	provider, err := NewProvider("github", forge.server.Client(), forge.server.URL+"/", "acme/widgets", "secret")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	ctx := context.Background()
+
+	created, err := provider.Create(ctx, &Release{TagName: "v1.0.0", Name: "Release v1.0.0", Body: "notas", Draft: true})
+	if err != nil {
+		t.Fatalf("Create falhou: %v", err)
+	}
+	if created.ID != "42" || created.URL == "" || !created.Draft {
+		t.Errorf("Release criada inesperada: %+v", created)
+	}
+
+	if auth := forge.headers[0].Get("Authorization"); auth != "token secret" {
+		t.Errorf("Authorization: esperava 'token secret', obteve '%s'", auth)
+	}
+
+	found, err := provider.Get(ctx, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Get falhou: %v", err)
+	}
+	if found.ID != "42" {
+		t.Errorf("Get deveria encontrar o rascunho pela lista, obteve %+v", found)
+	}
+
+	updated, err := provider.Update(ctx, &Release{TagName: "v1.0.0", Name: "Release v1.0.0", Body: "novas notas"})
+	if err != nil {
+		t.Fatalf("Update falhou: %v", err)
+	}
+	if updated.Body != "novas notas" || updated.Draft {
+		t.Errorf("Release atualizada inesperada: %+v", updated)
+	}
+
+	if err := provider.Delete(ctx, "v1.0.0"); err != nil {
+		t.Fatalf("Delete falhou: %v", err)
+	}
+
+	if _, err := provider.Get(ctx, "v1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get após Delete: esperava ErrNotFound, obteve %v", err)
+	}
+}
+
+func TestGitLabProvider(t *testing.T) {
+	releases := map[string]map[string]interface{}{}
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		const base = "/api/v4/projects/group%2Fsub%2Fwidgets/releases"
+		const tag = base + "/services%2Fapi%2Fv1.0.0"
+
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "POST " + base:
+			releases[body["tag_name"].(string)] = body
+			writeJSON(w, http.StatusCreated, body)
+		case "GET " + tag:
+			if release, ok := releases["services/api/v1.0.0"]; ok {
+				writeJSON(w, http.StatusOK, release)
+				return
+			}
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+		case "PUT " + tag:
+			for key, value := range body {
+				releases["services/api/v1.0.0"][key] = value
+			}
+			writeJSON(w, http.StatusOK, releases["services/api/v1.0.0"])
+		case "DELETE " + tag:
+			delete(releases, "services/api/v1.0.0")
+			writeJSON(w, http.StatusOK, map[string]string{})
+		default:
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusTeapot)
+		}
+	})
+
+	provider := NewGitLab(forge.server.Client(), forge.server.URL+"/api/v4", "group/sub/widgets", "secret")
+	ctx := context.Background()
+
+	if _, err := provider.Get(ctx, "services/api/v1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get antes de Create: esperava ErrNotFound, obteve %v", err)
+	}
+
+	created, err := provider.Create(ctx, &Release{TagName: "services/api/v1.0.0", Name: "api 1.0.0", Body: "notas", Target: "main"})
+	if err != nil {
+		t.Fatalf("Create falhou: %v", err)
+	}
+	if created.TagName != "services/api/v1.0.0" || created.Body != "notas" {
+		t.Errorf("Release criada inesperada: %+v", created)
+	}
+
+	if token := forge.headers[1].Get("PRIVATE-TOKEN"); token != "secret" {
+		t.Errorf("PRIVATE-TOKEN: esperava 'secret', obteve '%s'", token)
+	}
+
+	updated, err := provider.Update(ctx, &Release{TagName: "services/api/v1.0.0", Name: "api 1.0.0", Body: "novas notas"})
+	if err != nil {
+		t.Fatalf("Update falhou: %v", err)
+	}
+	if updated.Body != "novas notas" {
+		t.Errorf("Release atualizada inesperada: %+v", updated)
+	}
+
+	if err := provider.Delete(ctx, "services/api/v1.0.0"); err != nil {
+		t.Fatalf("Delete falhou: %v", err)
+	}
+}
+
+func TestGiteaProvider(t *testing.T) {
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/repos/acme/widgets/releases":
+			body["id"] = 7
+			writeJSON(w, http.StatusCreated, body)
+		case "GET /api/v1/repos/acme/widgets/releases/tags/v2.0.0-rc.1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": 7, "tag_name": "v2.0.0-rc.1", "prerelease": true})
+		default:
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "já existe"})
+		}
+	})
+
+	for _, name := range []string{"gitea", "forgejo"} {
+		provider, err := NewProvider(name, forge.server.Client(), forge.server.URL+"/api/v1", "acme/widgets", "secret")
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+
+		created, err := provider.Create(context.Background(), &Release{TagName: "v2.0.0-rc.1", Prerelease: true})
+		if err != nil {
+			t.Fatalf("%s: Create falhou: %v", provider.Name(), err)
+		}
+		if created.ID != "7" || !created.Prerelease {
+			t.Errorf("%s: release criada inesperada: %+v", provider.Name(), created)
+		}
+
+		found, err := provider.Get(context.Background(), "v2.0.0-rc.1")
+		if err != nil || found.ID != "7" {
+			t.Errorf("%s: Get falhou: %+v %v", provider.Name(), found, err)
+		}
+	}
+
+	provider, _ := NewProvider("gitea", forge.server.Client(), forge.server.URL+"/api/v1", "acme/widgets", "secret")
+	_, err := provider.Update(context.Background(), &Release{ID: "7", TagName: "v2.0.0-rc.1"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Esperava APIError 422, obteve %v", err)
+	}
+
+	if _, err := NewProvider("svn", nil, "", "acme/widgets", ""); err == nil {
+		t.Error("Provedor desconhecido deveria ser rejeitado")
+	}
+}
+
+func TestProvidersMatchConfig(t *testing.T) {
+	if !reflect.DeepEqual(Providers, config.ReleaseProviders) {
+		t.Errorf("config.ReleaseProviders %v deveria listar os mesmos provedores de release.Providers %v", config.ReleaseProviders, Providers)
+	}
+}
@@ -0,0 +1,92 @@
+package release
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pull request states.
+const (
+	PullRequestOpen   = "open"
+	PullRequestMerged = "merged"
+	PullRequestClosed = "closed"
+)
+
+// Merge methods of EnableAutoMerge.
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// PullRequest is a GitHub pull request or a GitLab merge request.
+type PullRequest struct {
+	// Number is the GitHub number or the GitLab IID, filled in by the provider.
+	Number int
+
+	Source string
+	Target string
+	Title  string
+	Body   string
+
+	// URL is the web page of the pull request.
+	URL string
+
+	// State is PullRequestOpen, PullRequestMerged or PullRequestClosed.
+	State string
+
+	// MergeCommit is the commit the merge created on Target, once merged.
+	MergeCommit string
+
+	// nodeID is GitHub's GraphQL ID, needed to enable auto-merge.
+	nodeID string
+}
+
+// PullRequester opens pull requests and follows them until they are merged.
+type PullRequester interface {
+	// OpenPullRequest opens pr, or returns the pull request already open
+	// from pr.Source into pr.Target.
+	OpenPullRequest(ctx context.Context, pr *PullRequest) (*PullRequest, error)
+
+	GetPullRequest(ctx context.Context, number int) (*PullRequest, error)
+
+	// EnableAutoMerge has the forge merge pr with method, one of the
+	// MergeMethod constants, as soon as its required checks pass.
+	EnableAutoMerge(ctx context.Context, pr *PullRequest, method string) error
+}
+
+// PullRequests returns the provider of the configured remote as a
+// PullRequester, for the providers that support it.
+func (r *ReleaseManager) PullRequests() (PullRequester, error) {
+	provider, err := r.Provider()
+	if err != nil {
+		return nil, err
+	}
+
+	requester, ok := provider.(PullRequester)
+	if !ok {
+		return nil, fmt.Errorf("o %s não suporta abrir pull requests; use GitHub ou GitLab", provider.Name())
+	}
+	return requester, nil
+}
+
+// BranchDeleter deletes branches through the provider API, which can remove
+// a protected branch that a push is not allowed to delete.
+type BranchDeleter interface {
+	DeleteBranch(ctx context.Context, branch string) error
+}
+
+// BranchDeleter returns the provider of the configured remote as a
+// BranchDeleter, for the providers that support it.
+func (r *ReleaseManager) BranchDeleter() (BranchDeleter, error) {
+	provider, err := r.Provider()
+	if err != nil {
+		return nil, err
+	}
+
+	deleter, ok := provider.(BranchDeleter)
+	if !ok {
+		return nil, fmt.Errorf("o %s não suporta remover branches pela API; use GitHub ou GitLab", provider.Name())
+	}
+	return deleter, nil
+}
@@ -0,0 +1,141 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGitHubPullRequests(t *testing.T) {
+	opened := false
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/acme/widgets/pulls":
+			if opened {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "A pull request already exists"})
+				return
+			}
+			opened = true
+			writeJSON(w, http.StatusCreated, map[string]interface{}{
+				"number": 7, "node_id": "PR_7", "html_url": "https://github.example.com/acme/widgets/pull/7", "state": "open",
+				"head": map[string]string{"ref": body["head"].(string)}, "base": map[string]string{"ref": body["base"].(string)},
+			})
+		case "GET /repos/acme/widgets/pulls":
+			if head := r.URL.Query().Get("head"); head != "acme:develop" {
+				t.Errorf("Esperava a busca por acme:develop, obteve %s", head)
+			}
+			writeJSON(w, http.StatusOK, []map[string]interface{}{{"number": 7, "state": "open"}})
+		case "GET /repos/acme/widgets/pulls/7":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"number": 7, "state": "closed", "merged": true, "merge_commit_sha": "abc123"})
+		case "POST /graphql":
+			if body["variables"].(map[string]interface{})["id"] != "PR_7" {
+				t.Errorf("Esperava o node_id do pull request, obteve %v", body["variables"])
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "Auto merge is not allowed"}}})
+		default:
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusTeapot)
+		}
+	})
+
+	provider := NewGitHub(forge.server.Client(), forge.server.URL, "acme/widgets", "secret")
+	ctx := context.Background()
+
+	pr, err := provider.OpenPullRequest(ctx, &PullRequest{Source: "develop", Target: "main", Title: "Release v1.1.0"})
+	if err != nil {
+		t.Fatalf("OpenPullRequest falhou: %v", err)
+	}
+	if pr.Number != 7 || pr.State != PullRequestOpen || pr.Source != "develop" || pr.Target != "main" {
+		t.Errorf("Pull request inesperado: %+v", pr)
+	}
+
+	existing, err := provider.OpenPullRequest(ctx, &PullRequest{Source: "develop", Target: "main", Title: "Release v1.1.0"})
+	if err != nil || existing.Number != 7 {
+		t.Errorf("Esperava o pull request já aberto, obteve %+v (%v)", existing, err)
+	}
+
+	merged, err := provider.GetPullRequest(ctx, 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest falhou: %v", err)
+	}
+	if merged.State != PullRequestMerged || merged.MergeCommit != "abc123" {
+		t.Errorf("Esperava o pull request mergeado em abc123, obteve %+v", merged)
+	}
+
+	if err := provider.EnableAutoMerge(ctx, pr, MergeMethodSquash); err == nil {
+		t.Error("Esperava o erro do GraphQL ao habilitar o auto-merge")
+	}
+}
+
+func TestGitLabMergeRequests(t *testing.T) {
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		const base = "/api/v4/projects/group%2Fwidgets/merge_requests"
+
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "POST " + base:
+			writeJSON(w, http.StatusConflict, map[string]interface{}{"message": []string{"Another open merge request already exists"}})
+		case "GET " + base:
+			writeJSON(w, http.StatusOK, []map[string]interface{}{{"iid": 3, "state": "opened", "source_branch": "develop", "target_branch": "main"}})
+		case "GET " + base + "/3":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"iid": 3, "state": "merged", "squash_commit_sha": "def456"})
+		case "PUT " + base + "/3/merge":
+			if body["merge_when_pipeline_succeeds"] != true || body["squash"] != true {
+				t.Errorf("Corpo do auto-merge inesperado: %v", body)
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"iid": 3})
+		default:
+			t.Errorf("Requisição inesperada: %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusTeapot)
+		}
+	})
+
+	provider := NewGitLab(forge.server.Client(), forge.server.URL+"/api/v4", "group/widgets", "secret")
+	ctx := context.Background()
+
+	pr, err := provider.OpenPullRequest(ctx, &PullRequest{Source: "develop", Target: "main", Title: "Release v1.1.0"})
+	if err != nil {
+		t.Fatalf("OpenPullRequest falhou: %v", err)
+	}
+	if pr.Number != 3 || pr.State != PullRequestOpen {
+		t.Errorf("Esperava o merge request já aberto, obteve %+v", pr)
+	}
+
+	if err := provider.EnableAutoMerge(ctx, pr, MergeMethodSquash); err != nil {
+		t.Fatalf("EnableAutoMerge falhou: %v", err)
+	}
+
+	merged, err := provider.GetPullRequest(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetPullRequest falhou: %v", err)
+	}
+	if merged.State != PullRequestMerged || merged.MergeCommit != "def456" {
+		t.Errorf("Esperava o merge request mergeado em def456, obteve %+v", merged)
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ctx := context.Background()
+
+	github := NewGitHub(forge.server.Client(), forge.server.URL, "acme/widgets", "secret")
+	if err := github.DeleteBranch(ctx, "feature/SHOP-7 cart"); err != nil {
+		t.Fatalf("DeleteBranch no GitHub falhou: %v", err)
+	}
+
+	gitlab := NewGitLab(forge.server.Client(), forge.server.URL+"/api/v4", "group/widgets", "secret")
+	if err := gitlab.DeleteBranch(ctx, "feature/SHOP-7"); err != nil {
+		t.Fatalf("DeleteBranch no GitLab falhou: %v", err)
+	}
+
+	expected := []string{
+		"DELETE /repos/acme/widgets/git/refs/heads/feature/SHOP-7%20cart",
+		"DELETE /api/v4/projects/group%2Fwidgets/repository/branches/feature%2FSHOP-7",
+	}
+	if !reflect.DeepEqual(forge.requests, expected) {
+		t.Errorf("Requisições: esperava %v, obteve %v", expected, forge.requests)
+	}
+}
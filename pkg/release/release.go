@@ -1,51 +1,52 @@
 package release
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
+	"net/url"
 	"os/exec"
 	"strings"
 
 	"github.com/be-tech/version-manager/internal/utils"
 	"github.com/be-tech/version-manager/pkg/config"
-	"github.com/joho/godotenv"
-)
-
-const (
-	githubAPIBaseURL = "https://api.github.com"
-	gitlabAPIBaseURL = "https://gitlab.com/api/v4"
+	"github.com/be-tech/version-manager/pkg/remote"
 )
 
 type ReleaseManager struct {
-	config *config.Config
-	logger *utils.Logger
-	gitCmd *utils.GitCommands
+	config      *config.Config
+	logger      utils.Logger
+	gitCmd      *utils.GitCommands
+	client      *http.Client
+	credentials *CredentialChain
 }
 
-type GitHubReleaseRequest struct {
-	TagName         string `json:"tag_name"`
-	TargetCommitish string `json:"target_commitish"`
-	Name            string `json:"name"`
-	Body            string `json:"body"`
-	Draft           bool   `json:"draft"`
-	Prerelease      bool   `json:"prerelease"`
-}
+// tokenEnvVars names the environment variable holding each provider's token.
+var tokenEnvVars = map[string]string{
+	"github":  "GITHUB_TOKEN",
+	"gitlab":  "GITLAB_TOKEN",
+	"gitea":   "GITEA_TOKEN",
+	"forgejo": "FORGEJO_TOKEN",
 
-type GitLabReleaseRequest struct {
-	Name        string `json:"name"`
-	TagName     string `json:"tag_name"`
-	Description string `json:"description"`
+	// A Bitbucket token may also be "user:app-password".
+	"bitbucket":        "BITBUCKET_TOKEN",
+	"bitbucket-server": "BITBUCKET_TOKEN",
 }
 
 func NewReleaseManager(config *config.Config) *ReleaseManager {
+	return NewReleaseManagerWithTransport(config, nil)
+}
+
+// NewReleaseManagerWithTransport creates a ReleaseManager whose API requests go
+// through transport. A nil transport uses http.DefaultTransport.
+func NewReleaseManagerWithTransport(config *config.Config, transport http.RoundTripper) *ReleaseManager {
 	return &ReleaseManager{
-		config: config,
-		logger: utils.NewLogger(),
-		gitCmd: utils.NewGitCommands(&defaultCommandRunner{}),
+		config:      config,
+		logger:      utils.NewLogger(),
+		gitCmd:      utils.NewGitCommands(&defaultCommandRunner{}),
+		client:      &http.Client{Transport: transport},
+		credentials: NewCredentialChain(),
 	}
 }
 
@@ -62,183 +63,278 @@ func (r *defaultCommandRunner) Output(name string, args ...string) ([]byte, erro
 	return cmd.Output()
 }
 
+// CreateRelease publishes the release of tagVersion. When the tag already has
+// a release, it is updated, kept or reported as an error according to
+// config.ReleaseExisting.
 func (r *ReleaseManager) CreateRelease(tagVersion string) error {
-	switch r.config.RepoType {
-	case "github":
-		return r.createGitHubRelease(tagVersion)
-	case "gitlab":
-		return r.createGitLabRelease(tagVersion)
-	default:
-		return fmt.Errorf("tipo de repositório não suportado: %s", r.config.RepoType)
-	}
-}
-
-func (r *ReleaseManager) getRepoFullName() (string, error) {
-	// Executando o comando git diretamente sem tentar acessar o campo privado runner
-	cmd := exec.Command("git", "remote", "get-url", r.config.Remote)
-	output, err := cmd.Output()
+	provider, err := r.Provider()
 	if err != nil {
-		return "", fmt.Errorf("falha ao obter URL do repositório: %v", err)
+		return err
 	}
 
-	repoURL := strings.TrimSpace(string(output))
-	repoURL = strings.TrimSuffix(repoURL, ".git")
-
-	var repoFullName string
-
-	if strings.Contains(repoURL, "github.com") {
-		parts := strings.Split(repoURL, "github.com/")
-		if len(parts) > 1 {
-			repoFullName = parts[1]
+	// Resolve the assets first, so that a missing file does not leave a
+	// release behind without them.
+	var assets []Asset
+	if len(r.config.ReleaseAssets) > 0 {
+		if _, ok := provider.(AssetUploader); !ok {
+			return fmt.Errorf("o %s não suporta anexar arquivos à release", provider.Name())
 		}
-	} else if strings.Contains(repoURL, "gitlab.com") {
-		parts := strings.Split(repoURL, "gitlab.com/")
-		if len(parts) > 1 {
-			repoFullName = parts[1]
+		if assets, err = r.assets(); err != nil {
+			return err
 		}
-	} else {
-		return "", fmt.Errorf("formato de URL do repositório não reconhecido: %s", repoURL)
 	}
 
-	if repoFullName == "" {
-		return "", fmt.Errorf("não foi possível extrair o nome completo do repositório da URL: %s", repoURL)
+	ctx := context.Background()
+
+	release := &Release{
+		TagName:    tagVersion,
+		Target:     r.config.DestinationBranch,
+		Name:       r.config.ReleaseTitle,
+		Body:       r.config.ReleaseNotes,
+		Draft:      r.config.ReleaseDraft,
+		Prerelease: strings.Contains(tagVersion, "-"),
+		MakeLatest: r.config.ReleaseMakeLatest,
+		ReleasedAt: r.config.ReleasedAt,
 	}
 
-	return repoFullName, nil
-}
+	existing, err := r.existingRelease(ctx, provider, tagVersion)
+	if err != nil {
+		return err
+	}
 
-func (r *ReleaseManager) getToken() (string, error) {
-	_ = godotenv.Load()
-	_ = godotenv.Load(".env")
+	var result *Release
 
-	_, err := os.Stat("../.env")
-	if err == nil {
-		_ = godotenv.Load("../.env")
-	}
+	switch {
+	case existing == nil:
+		r.logger.Info("Criando release no %s...", provider.Name())
+		if result, err = r.createRelease(ctx, provider, release); err != nil {
+			return err
+		}
+		r.logSuccess("Release criada com sucesso no %s", provider, result)
 
-	workDir, err := os.Getwd()
-	if err == nil {
-		_ = godotenv.Load(workDir + "/.env")
-	}
+	case r.config.ReleaseExisting == config.ExistingSkip:
+		r.logger.Warning("A tag %s já tem uma release no %s; mantida sem alterações", tagVersion, provider.Name())
+		return nil
 
-	var envVar string
-	if r.config.RepoType == "github" {
-		envVar = "GITHUB_TOKEN"
-	} else {
-		envVar = "GITLAB_TOKEN"
+	case r.config.ReleaseExisting == config.ExistingUpdate:
+		r.logger.Info("Atualizando a release existente no %s...", provider.Name())
+		release.ID = existing.ID
+		if result, err = provider.Update(ctx, release); err != nil {
+			return err
+		}
+		r.logSuccess("Release atualizada com sucesso no %s", provider, result)
+
+	default:
+		return fmt.Errorf("a tag %s já tem uma release no %s; use --release-existing update ou skip para reaproveitá-la", tagVersion, provider.Name())
 	}
 
-	token := os.Getenv(envVar)
-	if token == "" {
-		return "", fmt.Errorf("token de acesso não encontrado. Configure a variável de ambiente %s", envVar)
+	if result.TagName == "" {
+		result.TagName = tagVersion
 	}
 
-	return token, nil
+	if len(assets) > 0 {
+		return r.uploadAssets(ctx, provider.(AssetUploader), result, assets)
+	}
+	return nil
 }
 
-func (r *ReleaseManager) createGitHubRelease(tagVersion string) error {
-	r.logger.Info("Criando release no GitHub...")
+// PublishRelease turns the draft release of tag into a published one.
+func (r *ReleaseManager) PublishRelease(tag string) error {
+	provider, err := r.Provider()
+	if err != nil {
+		return err
+	}
 
-	token, err := r.getToken()
+	ctx := context.Background()
+
+	release, err := provider.Get(ctx, tag)
+	if isNotFound(err) {
+		return fmt.Errorf("a tag %s não tem release no %s", tag, provider.Name())
+	}
 	if err != nil {
 		return err
 	}
 
-	repoFullName, err := r.getRepoFullName()
+	if !release.Draft {
+		r.logger.Info("A release %s já está publicada no %s", tag, provider.Name())
+		return nil
+	}
+
+	release.Draft = false
+	release.MakeLatest = r.config.ReleaseMakeLatest
+
+	published, err := provider.Update(ctx, release)
 	if err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/repos/%s/releases", githubAPIBaseURL, repoFullName)
+	r.logSuccess("Release publicada com sucesso no %s", provider, published)
+	return nil
+}
+
+// createRelease creates release, retrying transient failures. The failed
+// request may still have created the release, so it is looked up by tag
+// before each retry and returned when found.
+func (r *ReleaseManager) createRelease(ctx context.Context, provider Provider, release *Release) (*Release, error) {
+	for attempt := 1; ; attempt++ {
+		created, err := provider.Create(ctx, release)
+
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt == maxAttempts {
+			return created, err
+		}
+
+		r.logger.Warning("Falha temporária ao criar a release (%v); tentando novamente...", err)
 
-	isPrerelease := strings.Contains(tagVersion, "-")
+		if wait := retryable.wait(attempt); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return nil, retryable.err
+			}
+		}
+
+		// Creating a Bitbucket release again is harmless, and its tag always exists.
+		if releasesAreTags(provider) {
+			continue
+		}
 
-	releaseData := GitHubReleaseRequest{
-		TagName:         "v" + tagVersion,
-		TargetCommitish: r.config.DestinationBranch,
-		Name:            r.config.ReleaseTitle,
-		Body:            r.config.ReleaseNotes,
-		Draft:           false,
-		Prerelease:      isPrerelease,
+		existing, err := provider.Get(ctx, release.TagName)
+		if err == nil {
+			r.logger.Info("A release %s foi criada apesar da falha", release.TagName)
+			return existing, nil
+		}
+		if !isNotFound(err) {
+			return nil, fmt.Errorf("falha ao verificar se a release %s foi criada: %v", release.TagName, err)
+		}
 	}
+}
 
-	jsonData, err := json.Marshal(releaseData)
-	if err != nil {
-		return fmt.Errorf("erro ao serializar dados da release: %v", err)
+// releasesAreTags reports whether the releases of provider are the tags
+// themselves, which are pushed before the release is created.
+func releasesAreTags(provider Provider) bool {
+	switch provider.(type) {
+	case *BitbucketCloud, *BitbucketServer:
+		return true
 	}
+	return false
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+// existingRelease returns the release of tag, or nil when it has none. On
+// Bitbucket the release is the tag itself, which is pushed first, so there is
+// nothing to look up; a dry run does not look it up either.
+func (r *ReleaseManager) existingRelease(ctx context.Context, provider Provider, tag string) (*Release, error) {
+	if releasesAreTags(provider) || r.config.DryRun {
+		return nil, nil
+	}
+
+	existing, err := provider.Get(ctx, tag)
+	if isNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %v", err)
+		return nil, fmt.Errorf("falha ao procurar release existente para %s: %v", tag, err)
 	}
+	return existing, nil
+}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("Authorization", "token "+token)
-	req.Header.Set("Content-Type", "application/json")
+func (r *ReleaseManager) logSuccess(message string, provider Provider, release *Release) {
+	if release.URL != "" {
+		r.logger.Success(message+": %s", provider.Name(), release.URL)
+	} else {
+		r.logger.Success(message+"!", provider.Name())
+	}
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// assets resolves the configured asset patterns and appends their checksums.
+func (r *ReleaseManager) assets() ([]Asset, error) {
+	assets, err := ResolveAssets(r.config.ReleaseAssets)
 	if err != nil {
-		return fmt.Errorf("erro ao enviar requisição: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("falha ao criar release (código %d): %s", resp.StatusCode, string(bodyBytes))
+	checksums, err := Checksums(assets)
+	if err != nil {
+		return nil, err
 	}
 
-	r.logger.Success("Release criada com sucesso no GitHub!")
-	return nil
+	return append(assets, checksums), nil
 }
 
-func (r *ReleaseManager) createGitLabRelease(tagVersion string) error {
-	r.logger.Info("Criando release no GitLab...")
+// uploadAssets attaches assets to release one at a time, reporting progress.
+// The first failure stops the upload and names the asset that broke.
+func (r *ReleaseManager) uploadAssets(ctx context.Context, uploader AssetUploader, release *Release, assets []Asset) error {
+	for i, asset := range assets {
+		r.logger.Info("Enviando asset %d/%d: %s (%s)...", i+1, len(assets), asset.Name, assetSize(asset))
 
-	token, err := r.getToken()
-	if err != nil {
-		return err
+		assetURL, err := uploader.UploadAsset(ctx, release, asset)
+		if err != nil {
+			return fmt.Errorf("falha ao enviar o asset %s (%d/%d): %v", asset.Name, i+1, len(assets), err)
+		}
+
+		if assetURL != "" {
+			r.logger.Success("Asset %s enviado: %s", asset.Name, assetURL)
+		} else {
+			r.logger.Success("Asset %s enviado", asset.Name)
+		}
 	}
+	return nil
+}
 
-	repoFullName, err := r.getRepoFullName()
+// Provider returns the release provider for the configured remote and
+// RepoType, authenticated with the provider's token. An empty RepoType is
+// detected from the remote's host.
+func (r *ReleaseManager) Provider() (Provider, error) {
+	repo, err := r.repository()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	repoFullName = strings.Replace(repoFullName, "/", "%2F", -1)
-	url := fmt.Sprintf("%s/projects/%s/releases", gitlabAPIBaseURL, repoFullName)
-
-	releaseData := GitLabReleaseRequest{
-		Name:        r.config.ReleaseTitle,
-		TagName:     "v" + tagVersion,
-		Description: r.config.ReleaseNotes,
+	name := r.config.RepoType
+	if name == "" {
+		if name = r.config.ProviderFor(repo.Host); name == "" {
+			return nil, fmt.Errorf("não foi possível detectar o provedor de %s; informe-o com --release", repo.Host)
+		}
 	}
+	if _, ok := tokenEnvVars[name]; !ok {
+		return nil, fmt.Errorf("tipo de repositório não suportado: %s", name)
+	}
+
+	apiURL := r.config.APIURLFor(repo.Host, name)
 
-	jsonData, err := json.Marshal(releaseData)
+	token, err := r.token(name, repo.Host, apiURL)
 	if err != nil {
-		return fmt.Errorf("erro ao serializar dados da release: %v", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	return NewProvider(name, r.client, apiURL, repo.Path, token)
+}
+
+// repository returns the repository of the configured remote.
+func (r *ReleaseManager) repository() (*remote.URL, error) {
+	rawURL, err := r.gitCmd.RemoteURL(r.config.Remote)
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %v", err)
+		return nil, fmt.Errorf("falha ao obter URL do repositório: %v", err)
 	}
 
-	req.Header.Set("PRIVATE-TOKEN", token)
-	req.Header.Set("Content-Type", "application/json")
+	return remote.Parse(rawURL)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("erro ao enviar requisição: %v", err)
+// token returns the token of provider at host from the credential chain,
+// reporting where it was found with the secret masked.
+func (r *ReleaseManager) token(provider string, host string, apiURL string) (string, error) {
+	apiHost := ""
+	if parsed, err := url.Parse(apiURL); err == nil {
+		apiHost = parsed.Hostname()
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("falha ao criar release (código %d): %s", resp.StatusCode, string(bodyBytes))
+	credential, err := r.credentials.Resolve(provider, host, apiHost)
+	if err != nil && r.config.DryRun {
+		r.logger.Warning("Token para %s não encontrado; a release real falharia", host)
+		return "<" + tokenEnvVars[provider] + ">", nil
+	}
+	if err != nil {
+		return "", err
 	}
 
-	r.logger.Success("Release criada com sucesso no GitLab!")
-	return nil
+	r.logger.Info("Token para %s obtido de %s: %s", host, credential.Source, credential.Masked())
+	return credential.Token, nil
 }
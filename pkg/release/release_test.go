@@ -0,0 +1,181 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/be-tech/version-manager/internal/utils"
+	"github.com/be-tech/version-manager/pkg/config"
+)
+
+// remoteRunner responde ao "git remote get-url" com url
+type remoteRunner struct {
+	url string
+}
+
+func (r *remoteRunner) Run(name string, args ...string) ([]byte, error) {
+	return r.Output(name, args...)
+}
+
+func (r *remoteRunner) Output(name string, args ...string) ([]byte, error) {
+	return []byte(r.url + "\n"), nil
+}
+
+// newTestReleaseManager cria um ReleaseManager do GitHub apontando para forge
+func newTestReleaseManager(t *testing.T, forge *fakeForge, cfg *config.Config) *ReleaseManager {
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	cfg.Remote = "origin"
+	cfg.DestinationBranch = "main"
+	cfg.Hosts = []config.Host{{Name: "github.com", Provider: "github", APIURL: forge.server.URL}}
+
+	return &ReleaseManager{
+		config:      cfg,
+		logger:      utils.NewLogger(),
+		gitCmd:      utils.NewGitCommands(&remoteRunner{url: "git@github.com:acme/widgets.git"}),
+		client:      forge.server.Client(),
+		credentials: NewCredentialChain(),
+	}
+}
+
+// existingReleaseForge simula um GitHub onde a tag v1.0.0 já tem a release 42
+func existingReleaseForge(t *testing.T) *fakeForge {
+	return newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/acme/widgets/releases/tags/v1.0.0":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": 42, "tag_name": "v1.0.0", "name": "antiga"})
+		case "PATCH /repos/acme/widgets/releases/42":
+			body["id"] = 42
+			writeJSON(w, http.StatusOK, body)
+		default:
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		}
+	})
+}
+
+func TestCreateReleaseExistingPolicies(t *testing.T) {
+	testCases := []struct {
+		policy   string
+		wantErr  bool
+		requests []string
+	}{
+		{config.ExistingFail, true, []string{"GET /repos/acme/widgets/releases/tags/v1.0.0"}},
+		{config.ExistingSkip, false, []string{"GET /repos/acme/widgets/releases/tags/v1.0.0"}},
+		{config.ExistingUpdate, false, []string{"GET /repos/acme/widgets/releases/tags/v1.0.0", "PATCH /repos/acme/widgets/releases/42"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policy, func(t *testing.T) {
+			forge := existingReleaseForge(t)
+
+			cfg := config.NewConfig()
+			cfg.ReleaseExisting = tc.policy
+			cfg.ReleaseNotes = "novas notas"
+
+			err := newTestReleaseManager(t, forge, cfg).CreateRelease("v1.0.0")
+			if tc.wantErr && (err == nil || !strings.Contains(err.Error(), "já tem uma release")) {
+				t.Errorf("Esperava erro de release existente, obteve %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Erro inesperado: %v", err)
+			}
+
+			if strings.Join(forge.requests, "\n") != strings.Join(tc.requests, "\n") {
+				t.Errorf("Esperava as requisições %v, obteve %v", tc.requests, forge.requests)
+			}
+		})
+	}
+}
+
+func TestCreateDraftRelease(t *testing.T) {
+	var created map[string]interface{}
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/acme/widgets/releases":
+			created = body
+			body["id"] = 7
+			writeJSON(w, http.StatusCreated, body)
+		case "GET /repos/acme/widgets/releases":
+			writeJSON(w, http.StatusOK, []interface{}{})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	})
+
+	cfg := config.NewConfig()
+	cfg.ReleaseDraft = true
+	cfg.ReleaseMakeLatest = "false"
+
+	if err := newTestReleaseManager(t, forge, cfg).CreateRelease("v1.0.0"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if created["draft"] != true || created["make_latest"] != "false" {
+		t.Errorf("Esperava rascunho com make_latest false, obteve %v", created)
+	}
+}
+
+func TestPublishRelease(t *testing.T) {
+	var patched map[string]interface{}
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/acme/widgets/releases":
+			writeJSON(w, http.StatusOK, []map[string]interface{}{
+				{"id": 7, "tag_name": "v1.0.0", "name": "v1.0.0", "body": "notas", "draft": true},
+			})
+		case "PATCH /repos/acme/widgets/releases/7":
+			patched = body
+			writeJSON(w, http.StatusOK, body)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	})
+
+	cfg := config.NewConfig()
+	cfg.ReleaseMakeLatest = "true"
+
+	if err := newTestReleaseManager(t, forge, cfg).PublishRelease("v1.0.0"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if patched["draft"] != false || patched["make_latest"] != "true" || patched["body"] != "notas" {
+		t.Errorf("Esperava publicar o rascunho mantendo as notas, obteve %v", patched)
+	}
+
+	if err := newTestReleaseManager(t, forge, cfg).PublishRelease("v2.0.0"); err == nil {
+		t.Error("Esperava erro para tag sem release")
+	}
+}
+
+func TestGitLabReleasedAt(t *testing.T) {
+	var created map[string]interface{}
+
+	forge := newFakeForge(t, func(f *fakeForge, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+		created = body
+		writeJSON(w, http.StatusCreated, body)
+	})
+
+	releasedAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	provider := NewGitLab(forge.server.Client(), forge.server.URL, "group/widgets", "secret")
+
+	release, err := provider.Create(context.Background(), &Release{TagName: "v1.0.0", ReleasedAt: releasedAt})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if created["released_at"] != "2030-01-02T15:04:05Z" || !release.ReleasedAt.Equal(releasedAt) {
+		t.Errorf("Esperava released_at 2030-01-02T15:04:05Z, obteve %v", created["released_at"])
+	}
+
+	if _, err := provider.Create(context.Background(), &Release{TagName: "v1.0.1"}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if _, ok := created["released_at"]; ok {
+		t.Errorf("released_at não deveria ser enviado quando vazio, obteve %v", created)
+	}
+}
@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL is a git remote URL reduced to the parts the tool needs: the host and
+// the repository path, e.g. "github.com" and "acme/widgets".
+type URL struct {
+	Host string
+	Path string
+}
+
+// Parse understands https, ssh and scp-like remote URLs:
+//
+//	https://github.com/acme/widgets.git
+//	ssh://git@gitlab.example.com:2222/group/sub/widgets.git
+//	git@github.com:acme/widgets.git
+func Parse(rawURL string) (*URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+
+	var host, path string
+
+	if strings.Contains(rawURL, "://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("URL do repositório inválida %q: %v", rawURL, err)
+		}
+		host = parsed.Hostname()
+		path = parsed.Path
+	} else if at := strings.Index(rawURL, "@"); at != -1 && strings.Contains(rawURL[at:], ":") {
+		rest := rawURL[at+1:]
+		colon := strings.Index(rest, ":")
+		host = rest[:colon]
+		path = rest[colon+1:]
+	} else if colon := strings.Index(rawURL, ":"); colon > 0 && !strings.Contains(rawURL[:colon], "/") {
+		host = rawURL[:colon]
+		path = rawURL[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	if host == "" || path == "" || !strings.Contains(path, "/") {
+		return nil, fmt.Errorf("formato de URL do repositório não reconhecido: %s", rawURL)
+	}
+
+	return &URL{Host: strings.ToLower(host), Path: path}, nil
+}
+
+// WebURL returns the https address of the repository page.
+func (u *URL) WebURL() string {
+	return "https://" + u.Host + "/" + u.Path
+}
+
+// Owner returns everything before the repository name, e.g. the GitLab group.
+func (u *URL) Owner() string {
+	return u.Path[:strings.LastIndex(u.Path, "/")]
+}
+
+// Name returns the repository name.
+func (u *URL) Name() string {
+	return u.Path[strings.LastIndex(u.Path, "/")+1:]
+}
@@ -0,0 +1,124 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BumpAuto asks for the bump to be inferred from Conventional Commits. It must
+// be resolved with ResolveBump before calling GenerateNewTag.
+const BumpAuto = "auto"
+
+// Commit is a commit message as read from git log.
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// ConventionalCommit is the parsed header of a Conventional Commit.
+type ConventionalCommit struct {
+	Type     string
+	Scope    string
+	Breaking bool
+}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?: \S`)
+
+var breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// ParseConventional parses the commit header. ok is false when the subject
+// does not follow the Conventional Commits format.
+func ParseConventional(commit Commit) (cc ConventionalCommit, ok bool) {
+	match := conventionalHeader.FindStringSubmatch(commit.Subject)
+	if match == nil {
+		return ConventionalCommit{}, false
+	}
+
+	return ConventionalCommit{
+		Type:     strings.ToLower(match[1]),
+		Scope:    match[2],
+		Breaking: match[3] == "!" || breakingFooter.MatchString(commit.Body),
+	}, true
+}
+
+// InferBump returns the bump implied by commits: "major" for breaking changes,
+// "minor" for feat, "patch" for fix and perf, or "" when no commit warrants a
+// release. It also returns the commits that drove the decision.
+func (h *Handler) InferBump(commits []Commit) (string, []Commit) {
+	levels := map[string]int{"": 0, "patch": 1, "minor": 2, "major": 3}
+
+	bump := ""
+	var drivers []Commit
+
+	for _, commit := range commits {
+		cc, ok := ParseConventional(commit)
+		if !ok {
+			continue
+		}
+
+		commitBump := ""
+		switch {
+		case cc.Breaking:
+			commitBump = "major"
+		case cc.Type == "feat":
+			commitBump = "minor"
+		case cc.Type == "fix" || cc.Type == "perf":
+			commitBump = "patch"
+		default:
+			continue
+		}
+
+		if levels[commitBump] > levels[bump] {
+			bump = commitBump
+			drivers = nil
+		}
+		if commitBump == bump {
+			drivers = append(drivers, commit)
+		}
+	}
+
+	return bump, drivers
+}
+
+// ResolveBump replaces BumpAuto with the bump inferred from the commits since
+// currentTag, falling back to "patch" when none of them is a feat, fix, perf
+// or breaking change. A Handler with a channel returns the prerelease type of
+// ChannelBump instead. Other version types are returned unchanged.
+func (h *Handler) ResolveBump(currentTag string, versionType string, commits []Commit) (string, []Commit) {
+	if versionType != BumpAuto {
+		return versionType, nil
+	}
+
+	bump, drivers := h.InferBump(commits)
+	if bump == "" {
+		bump, drivers = "patch", nil
+	}
+
+	return h.ChannelBump(currentTag, bump), drivers
+}
+
+// ChannelBump turns bump, one of major, minor or patch, into the version type
+// that releases it on the channel of h. When currentTag is already a
+// prerelease whose version includes bump, as 1.3.0-rc.1 or 1.3.0-alpha.4 do
+// for a minor bump, the prerelease continues on the channel of h; otherwise a
+// new one starts with the matching pre* type. Without a channel, bump is
+// returned unchanged.
+func (h *Handler) ChannelBump(currentTag string, bump string) string {
+	if h.preid == "" {
+		return bump
+	}
+
+	tag := strings.TrimPrefix(strings.TrimSpace(currentTag), h.prefix)
+	current, err := Parse(strings.TrimPrefix(tag, "v"))
+	if err == nil && len(current.Prerelease) > 0 {
+		covered := bump == "patch" ||
+			bump == "minor" && current.Patch == 0 ||
+			bump == "major" && current.Minor == 0 && current.Patch == 0
+		if covered {
+			return "prerelease"
+		}
+	}
+
+	return "pre" + bump
+}
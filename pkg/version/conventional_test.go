@@ -0,0 +1,58 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestInferBump(t *testing.T) {
+	handler := NewHandler()
+
+	testCases := []struct {
+		name     string
+		commits  []Commit
+		expected string
+		drivers  int
+	}{
+		{"No Commits", nil, "", 0},
+		{"Only Chores", []Commit{{Subject: "chore: deps"}, {Subject: "docs(readme): typo"}}, "", 0},
+		{"Not Conventional", []Commit{{Subject: "Merge branch 'develop'"}}, "", 0},
+		{"Fix", []Commit{{Subject: "fix: crash"}, {Subject: "chore: deps"}}, "patch", 1},
+		{"Perf", []Commit{{Subject: "perf(db): cache"}}, "patch", 1},
+		{"Feat Wins Over Fix", []Commit{{Subject: "fix: a"}, {Subject: "feat(api): b"}, {Subject: "feat: c"}}, "minor", 2},
+		{"Bang Is Major", []Commit{{Subject: "feat: a"}, {Subject: "refactor!: drop v1"}}, "major", 1},
+		{"Footer Is Major", []Commit{{Subject: "fix: a", Body: "details\n\nBREAKING CHANGE: removed flag"}}, "major", 1},
+		{"Hyphen Footer Is Major", []Commit{{Subject: "fix: a", Body: "BREAKING-CHANGE: removed flag"}}, "major", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bump, drivers := handler.InferBump(tc.commits)
+			if bump != tc.expected {
+				t.Errorf("Esperava '%s' mas obteve '%s'", tc.expected, bump)
+			}
+			if len(drivers) != tc.drivers {
+				t.Errorf("Esperava %d commits responsáveis, obteve %d: %v", tc.drivers, len(drivers), drivers)
+			}
+		})
+	}
+}
+
+func TestResolveBump(t *testing.T) {
+	handler := NewHandler()
+
+	if bump, _ := handler.ResolveBump("", "minor", []Commit{{Subject: "feat!: a"}}); bump != "minor" {
+		t.Errorf("Tipos explícitos não devem ser alterados, obteve '%s'", bump)
+	}
+
+	if bump, _ := handler.ResolveBump("", BumpAuto, []Commit{{Subject: "feat: a"}}); bump != "minor" {
+		t.Errorf("auto com feat: esperava 'minor', obteve '%s'", bump)
+	}
+
+	if bump, _ := handler.ResolveBump("", BumpAuto, []Commit{{Subject: "chore: a"}}); bump != "patch" {
+		t.Errorf("auto sem commits relevantes: esperava 'patch', obteve '%s'", bump)
+	}
+
+	if _, err := handler.GenerateNewTag("v1.0.0", BumpAuto); err == nil {
+		t.Error("GenerateNewTag deve recusar 'auto' não resolvido")
+	}
+}
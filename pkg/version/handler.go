@@ -6,118 +6,142 @@ import (
 	"strings"
 )
 
-type Handler struct{}
+// BumpTypes lists the accepted version types. BumpAuto must be resolved with
+// ResolveBump before it reaches GenerateNewTag.
+var BumpTypes = []string{"major", "minor", "patch", "premajor", "preminor", "prepatch", "prerelease", BumpAuto}
+
+// IsBumpType reports whether versionType is one of BumpTypes.
+func IsBumpType(versionType string) bool {
+	for _, t := range BumpTypes {
+		if t == versionType {
+			return true
+		}
+	}
+	return false
+}
+
+type Handler struct {
+	prefix string
+	preid  string
+	tags   []string
+}
 
 func NewHandler() *Handler {
 	return &Handler{}
 }
 
+// NewHandlerWithPrefix creates a Handler whose tags start with prefix, e.g.
+// "v" or "release-". An empty prefix keeps the tag's own "v", if any.
+func NewHandlerWithPrefix(prefix string) *Handler {
+	return &Handler{prefix: prefix}
+}
+
+// NewHandlerWithChannel is like NewHandlerWithPrefix but names prereleases
+// after channel, e.g. "alpha" or "rc", instead of "pre".
+func NewHandlerWithChannel(prefix string, channel string) *Handler {
+	return &Handler{prefix: prefix, preid: channel}
+}
+
+// SetExistingTags gives the handler the tags already in the repository, so
+// that a new prerelease continues from the highest counter used for the same
+// version and channel, even when that tag is not reachable from the branch.
+func (h *Handler) SetExistingTags(tags []string) {
+	h.tags = tags
+}
+
 func (h *Handler) GenerateNewTag(currentTag, versionType string) (string, error) {
 	currentTag = strings.TrimSpace(currentTag)
 
-	if currentTag == "" {
-		if versionType == "premajor" {
-			return "v1.0.0-pre.0", nil
-		} else if versionType == "preminor" {
-			return "v0.1.0-pre.0", nil
-		} else if versionType == "prepatch" || versionType == "prerelease" {
-			return "v0.0.1-pre.0", nil
-		}
-		return "v1.0.0", nil
+	if versionType == BumpAuto {
+		return "", fmt.Errorf("the %q version type must be resolved from the commits first", BumpAuto)
 	}
 
-	tagVersion := currentTag
-	hasV := false
-	if strings.HasPrefix(currentTag, "v") {
-		tagVersion = currentTag[1:]
-		hasV = true
+	if h.prefix == "" {
+		return h.generateNewTag(currentTag, versionType)
 	}
 
-	isPre := false
-	preVersion := 0
-	mainVersion := tagVersion
-	if strings.Contains(tagVersion, "-pre") {
-		isPre = true
-		parts := strings.Split(tagVersion, "-pre.")
-		if len(parts) >= 2 {
-			mainVersion = parts[0]
-			preStr := parts[1]
-			preVersion, _ = strconv.Atoi(preStr)
-		}
+	newTag, err := h.generateNewTag(strings.TrimPrefix(currentTag, h.prefix), versionType)
+	if err != nil {
+		return "", err
 	}
 
-	parts := strings.Split(mainVersion, ".")
-	if len(parts) < 3 {
-		return "v1.0.0", nil
-	}
+	return h.prefix + strings.TrimPrefix(newTag, "v"), nil
+}
 
-	major, _ := strconv.Atoi(parts[0])
-	minor, _ := strconv.Atoi(parts[1])
-	patch, _ := strconv.Atoi(parts[2])
-
-	var newTag string
-
-	switch versionType {
-	case "major":
-		major++
-		minor = 0
-		patch = 0
-		newTag = fmt.Sprintf("%d.%d.%d", major, minor, patch)
-
-	case "minor":
-		minor++
-		patch = 0
-		newTag = fmt.Sprintf("%d.%d.%d", major, minor, patch)
-
-	case "patch":
-		patch++
-		newTag = fmt.Sprintf("%d.%d.%d", major, minor, patch)
-
-	case "premajor":
-		if isPre && strings.HasPrefix(versionType, "pre") {
-			preVersion++
-			newTag = fmt.Sprintf("%d.%d.%d-pre.%d", major, minor, patch, preVersion)
-		} else {
-			major++
-			minor = 0
-			patch = 0
-			newTag = fmt.Sprintf("%d.%d.%d-pre.0", major, minor, patch)
-		}
+func (h *Handler) generateNewTag(currentTag, versionType string) (string, error) {
+	hasV := currentTag == "" || strings.HasPrefix(currentTag, "v")
 
-	case "preminor":
-		if isPre && strings.HasPrefix(versionType, "pre") {
-			preVersion++
-			newTag = fmt.Sprintf("%d.%d.%d-pre.%d", major, minor, patch, preVersion)
-		} else {
-			minor++
-			patch = 0
-			newTag = fmt.Sprintf("%d.%d.%d-pre.0", major, minor, patch)
+	current := &Version{}
+	if currentTag != "" {
+		parsed, err := Parse(strings.TrimPrefix(currentTag, "v"))
+		if err != nil {
+			return "", fmt.Errorf("current tag %q is not a valid version: %v", currentTag, err)
 		}
+		current = parsed
+	}
 
-	case "prepatch":
-		if isPre && strings.HasPrefix(versionType, "pre") {
-			preVersion++
-			newTag = fmt.Sprintf("%d.%d.%d-pre.%d", major, minor, patch, preVersion)
-		} else {
-			patch++
-			newTag = fmt.Sprintf("%d.%d.%d-pre.0", major, minor, patch)
-		}
+	var next *Version
 
-	case "prerelease":
-		if isPre {
-			preVersion++
-			newTag = fmt.Sprintf("%d.%d.%d-pre.%d", major, minor, patch, preVersion)
-		} else {
-			newTag = fmt.Sprintf("%d.%d.%d-pre.0", major, minor, patch)
+	if explicit, err := Parse(strings.TrimPrefix(versionType, "v")); err == nil {
+		if currentTag != "" && explicit.Compare(current) <= 0 {
+			return "", fmt.Errorf("version %s must be greater than the current %s", explicit, current)
 		}
-
-	default:
-		newTag = fmt.Sprintf("%d.%d.%d", major, minor, patch)
+		next = explicit
+	} else if currentTag == "" && !strings.HasPrefix(versionType, "pre") && IsBumpType(versionType) {
+		// The first release is always 1.0.0, whatever the bump type.
+		next = &Version{Major: 1}
+	} else {
+		next, err = current.Bump(versionType, h.prereleaseID(current, versionType))
+		if err != nil {
+			return "", err
+		}
+		h.skipExistingPrereleases(next)
 	}
 
+	newTag := next.String()
 	if hasV {
 		newTag = "v" + newTag
 	}
 
 	return newTag, nil
 }
+
+// prereleaseID returns the identifier for a pre* bump: the configured one, or
+// else the current identifier for "prerelease" and "pre" for a new prerelease.
+func (h *Handler) prereleaseID(current *Version, versionType string) string {
+	if h.preid != "" {
+		return h.preid
+	}
+	if versionType == "prerelease" && current.IsPrerelease() {
+		return ""
+	}
+	return "pre"
+}
+
+// skipExistingPrereleases moves the counter of a channel prerelease past the
+// highest one among the existing tags for the same version and channel.
+func (h *Handler) skipExistingPrereleases(next *Version) {
+	if h.preid == "" || len(next.Prerelease) != 2 || next.Prerelease[0] != h.preid {
+		return
+	}
+
+	counter, err := strconv.ParseUint(next.Prerelease[1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	for _, tag := range h.tags {
+		existing, err := Parse(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(tag), h.prefix), "v"))
+		if err != nil || len(existing.Prerelease) != 2 || existing.Prerelease[0] != h.preid {
+			continue
+		}
+		if existing.Major != next.Major || existing.Minor != next.Minor || existing.Patch != next.Patch {
+			continue
+		}
+		if n, err := strconv.ParseUint(existing.Prerelease[1], 10, 64); err == nil && n >= counter {
+			counter = n + 1
+		}
+	}
+
+	next.Prerelease[1] = strconv.FormatUint(counter, 10)
+}
@@ -30,14 +30,31 @@ func TestGenerateNewTag(t *testing.T) {
 		{"Premajor New", "v1.0.0", "premajor", "v2.0.0-pre.0"},
 		{"Preminor New", "v1.0.0", "preminor", "v1.1.0-pre.0"},
 		{"Prepatch New", "v1.0.0", "prepatch", "v1.0.1-pre.0"},
-		{"Prerelease New", "v1.0.0", "prerelease", "v1.0.0-pre.0"},
+		{"Prerelease New", "v1.0.0", "prerelease", "v1.0.1-pre.0"},
 
-		// Casos para incremento de pré-lançamentos
-		{"Premajor Increment", "v1.0.0-pre.0", "premajor", "v1.0.0-pre.1"},
-		{"Preminor Increment", "v1.0.0-pre.0", "preminor", "v1.0.0-pre.1"},
-		{"Prepatch Increment", "v1.0.0-pre.0", "prepatch", "v1.0.0-pre.1"},
+		// Casos para incremento de pré-lançamentos (mesma semântica do npm semver)
+		{"Premajor Increment", "v1.0.0-pre.0", "premajor", "v2.0.0-pre.0"},
+		{"Preminor Increment", "v1.0.0-pre.0", "preminor", "v1.1.0-pre.0"},
+		{"Prepatch Increment", "v1.0.0-pre.0", "prepatch", "v1.0.1-pre.0"},
 		{"Prerelease Increment", "v1.0.0-pre.0", "prerelease", "v1.0.0-pre.1"},
 
+		// Finalização de pré-lançamentos
+		{"Major From Prerelease", "v2.0.0-pre.3", "major", "v2.0.0"},
+		{"Minor From Prerelease", "v1.1.0-pre.3", "minor", "v1.1.0"},
+		{"Patch From Prerelease", "v1.0.1-pre.3", "patch", "v1.0.1"},
+		{"Minor From Patch Prerelease", "v1.0.1-pre.3", "minor", "v1.1.0"},
+
+		// Tags antigas do pacote process e identificadores arbitrários
+		{"Legacy Beta", "v1.2.3-beta", "prerelease", "v1.2.3-beta.0"},
+		{"Legacy Beta Numbered", "v1.2.3-beta.4", "prerelease", "v1.2.3-beta.5"},
+		{"Dotted Identifiers", "v1.2.3-rc.1.linux", "prerelease", "v1.2.3-rc.2.linux"},
+		{"Build Metadata Dropped", "v1.2.3+build.7", "patch", "v1.2.4"},
+		{"Prerelease With Build", "v1.2.3-rc.1+sha.abc", "prerelease", "v1.2.3-rc.2"},
+
+		// Versão explícita
+		{"Explicit Version", "v1.2.3", "2.0.0-rc.1", "v2.0.0-rc.1"},
+		{"Initial Explicit Version", "", "1.0.0", "v1.0.0"},
+
 		// Casos sem prefixo 'v'
 		{"Major No V", "1.0.0", "major", "2.0.0"},
 		{"Minor No V", "1.0.0", "minor", "1.1.0"},
@@ -65,24 +82,102 @@ func TestGenerateNewTagWithInvalidInput(t *testing.T) {
 		name        string
 		currentTag  string
 		versionType string
+	}{
+		// Tags atuais inválidas devem gerar erro em vez de voltar para v1.0.0
+		{"Invalid Format", "v1.0", "major"},
+		{"Very Invalid Format", "not-a-version", "major"},
+		{"Leading Zero", "v01.0.0", "patch"},
+		{"Empty Prerelease Identifier", "v1.0.0-", "patch"},
+		{"Empty Version Type", "v1.0.0", ""},
+		{"Unknown Version Type", "v1.0.0", "huge"},
+		{"Explicit Version Not Greater", "v1.2.3", "1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := handler.GenerateNewTag(tc.currentTag, tc.versionType)
+			if err == nil {
+				t.Errorf("Para tag '%s' e tipo '%s', esperava erro mas obteve '%s'",
+					tc.currentTag, tc.versionType, result)
+			}
+		})
+	}
+}
+
+func TestGenerateNewTagWithChannel(t *testing.T) {
+	existing := []string{"v1.2.0", "v1.2.1-rc.0", "v1.2.1-rc.3", "v1.2.1-alpha.7", "v1.3.0-rc.1", "v1.2.1-rc.x"}
+
+	testCases := []struct {
+		name        string
+		channel     string
+		currentTag  string
+		versionType string
 		expected    string
 	}{
-		// Casos com formatos inválidos
-		{"Invalid Format", "v1.0", "major", "v1.0.0"},
-		{"Very Invalid Format", "not-a-version", "major", "v1.0.0"},
-		{"Empty Version Type", "v1.0.0", "", "v1.0.0"},
+		// O contador continua a partir da maior tag existente da mesma versão e canal
+		{"New Prerelease From Release", "rc", "v1.2.0", "prerelease", "v1.2.1-rc.4"},
+		{"Switch Channel", "rc", "v1.2.1-alpha.7", "prerelease", "v1.2.1-rc.4"},
+		{"Same Channel", "alpha", "v1.2.1-alpha.7", "prerelease", "v1.2.1-alpha.8"},
+		{"Preminor", "rc", "v1.2.0", "preminor", "v1.3.0-rc.2"},
+		{"Premajor Without Tags", "rc", "v1.2.0", "premajor", "v2.0.0-rc.0"},
+		{"Stable Bump Ignores Channel", "rc", "v1.2.1-rc.3", "patch", "v1.2.1"},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandlerWithChannel("", tc.channel)
+			handler.SetExistingTags(existing)
+
 			result, err := handler.GenerateNewTag(tc.currentTag, tc.versionType)
 			if err != nil {
 				t.Fatalf("Erro inesperado: %v", err)
 			}
-			// Para entradas inválidas, esperamos que o sistema ainda retorne algo razoável
 			if result != tc.expected {
-				t.Errorf("Para entrada inválida, esperava '%s' mas obteve '%s'",
-					tc.expected, result)
+				t.Errorf("Esperava '%s', obteve '%s'", tc.expected, result)
+			}
+		})
+	}
+
+	bump, _ := NewHandlerWithChannel("v", "alpha").ResolveBump("", BumpAuto, []Commit{{Subject: "feat: search"}})
+	if bump != "preminor" {
+		t.Errorf("Em um canal, ResolveBump deveria retornar 'preminor', obteve '%s'", bump)
+	}
+}
+
+func TestResolveBumpContinuesChannelPrerelease(t *testing.T) {
+	handler := NewHandlerWithChannel("v", "rc")
+	feat := []Commit{{Subject: "feat: checkout"}}
+
+	testCases := []struct {
+		name       string
+		currentTag string
+		commits    []Commit
+		expected   string
+		nextTag    string
+	}{
+		// A versão base 1.3.0 já cobre o feat, então o prerelease continua
+		{"Continue Minor", "v1.3.0-rc.1", feat, "prerelease", "v1.3.0-rc.2"},
+		{"Continue Patch", "v1.2.1-rc.0", []Commit{{Subject: "fix: total"}}, "prerelease", "v1.2.1-rc.1"},
+		{"Feat On Patch Prerelease", "v1.2.1-rc.0", feat, "preminor", "v1.3.0-rc.0"},
+		{"Breaking On Minor Prerelease", "v1.3.0-rc.1", []Commit{{Subject: "feat!: api"}}, "premajor", "v2.0.0-rc.0"},
+		// Promovida de alpha para rc, a 1.3.0 continua no novo canal
+		{"Other Channel", "v1.3.0-alpha.4", feat, "prerelease", "v1.3.0-rc.0"},
+		{"From Release", "v1.2.0", feat, "preminor", "v1.3.0-rc.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bump, _ := handler.ResolveBump(tc.currentTag, BumpAuto, tc.commits)
+			if bump != tc.expected {
+				t.Fatalf("Esperava '%s', obteve '%s'", tc.expected, bump)
+			}
+
+			next, err := handler.GenerateNewTag(tc.currentTag, bump)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if next != tc.nextTag {
+				t.Errorf("Esperava '%s', obteve '%s'", tc.nextTag, next)
 			}
 		})
 	}
@@ -0,0 +1,214 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a Semantic Versioning 2.0.0 version: MAJOR.MINOR.PATCH with
+// optional dot-separated prerelease identifiers and build metadata.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Parse parses a version as defined by semver.org. A leading "v" is not
+// accepted here; callers strip their tag prefix first.
+func Parse(s string) (*Version, error) {
+	match := semverPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	v := &Version{}
+	var err error
+
+	if v.Major, err = strconv.ParseUint(match[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid major version in %q: %v", s, err)
+	}
+	if v.Minor, err = strconv.ParseUint(match[2], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid minor version in %q: %v", s, err)
+	}
+	if v.Patch, err = strconv.ParseUint(match[3], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid patch version in %q: %v", s, err)
+	}
+
+	if match[4] != "" {
+		v.Prerelease = strings.Split(match[4], ".")
+	}
+	if match[5] != "" {
+		v.Build = strings.Split(match[5], ".")
+	}
+
+	return v, nil
+}
+
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// IsPrerelease reports whether v has prerelease identifiers.
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 as v has lower, equal or higher precedence than
+// other. Build metadata does not affect precedence.
+func (v *Version) Compare(other *Version) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// A version without prerelease has higher precedence than one with it.
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifiers compares numeric identifiers numerically and others in
+// ASCII order; numeric identifiers have lower precedence.
+func compareIdentifiers(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Bump returns the version that follows v for the given release type,
+// following the semantics of npm's semver.inc. preid names the prerelease
+// identifier, e.g. "pre", "beta" or "rc"; when empty, "prerelease" keeps the
+// current identifier and the other pre* types start a bare numeric prerelease.
+// Build metadata is always dropped.
+func (v *Version) Bump(releaseType string, preid string) (*Version, error) {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	next.Prerelease = append([]string(nil), v.Prerelease...)
+
+	switch releaseType {
+	case "major":
+		// 1.0.0-pre.1 is released as 1.0.0, not 2.0.0.
+		if v.Minor != 0 || v.Patch != 0 || !v.IsPrerelease() {
+			next.Major++
+		}
+		next.Minor, next.Patch, next.Prerelease = 0, 0, nil
+
+	case "minor":
+		if v.Patch != 0 || !v.IsPrerelease() {
+			next.Minor++
+		}
+		next.Patch, next.Prerelease = 0, nil
+
+	case "patch":
+		if !v.IsPrerelease() {
+			next.Patch++
+		}
+		next.Prerelease = nil
+
+	case "premajor":
+		next.Major, next.Minor, next.Patch, next.Prerelease = next.Major+1, 0, 0, nil
+		next.incrementPrerelease(preid)
+
+	case "preminor":
+		next.Minor, next.Patch, next.Prerelease = next.Minor+1, 0, nil
+		next.incrementPrerelease(preid)
+
+	case "prepatch":
+		next.Patch, next.Prerelease = next.Patch+1, nil
+		next.incrementPrerelease(preid)
+
+	case "prerelease":
+		if !v.IsPrerelease() {
+			next.Patch++
+		}
+		next.incrementPrerelease(preid)
+
+	default:
+		return nil, fmt.Errorf("unknown version type %q", releaseType)
+	}
+
+	return next, nil
+}
+
+// incrementPrerelease bumps the last numeric identifier, appending ".0" when
+// there is none, then switches to preid if it differs from the current one.
+func (v *Version) incrementPrerelease(preid string) {
+	if len(v.Prerelease) == 0 {
+		v.Prerelease = []string{"0"}
+	} else {
+		incremented := false
+		for i := len(v.Prerelease) - 1; i >= 0; i-- {
+			if n, err := strconv.ParseUint(v.Prerelease[i], 10, 64); err == nil {
+				v.Prerelease[i] = strconv.FormatUint(n+1, 10)
+				incremented = true
+				break
+			}
+		}
+		if !incremented {
+			v.Prerelease = append(v.Prerelease, "0")
+		}
+	}
+
+	if preid == "" {
+		return
+	}
+
+	if v.Prerelease[0] != preid {
+		v.Prerelease = []string{preid, "0"}
+		return
+	}
+	if _, err := strconv.ParseUint(v.Prerelease[1], 10, 64); err != nil {
+		v.Prerelease = []string{preid, "0"}
+	}
+}
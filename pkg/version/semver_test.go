@@ -0,0 +1,107 @@
+package version
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	valid := []string{
+		"0.0.0",
+		"1.2.3",
+		"1.2.3-alpha",
+		"1.2.3-alpha.1",
+		"1.2.3-0.3.7",
+		"1.2.3-x.7.z.92",
+		"1.2.3-x-y-z.--",
+		"1.2.3+build.1",
+		"1.2.3-beta+exp.sha.5114f85",
+		"1.0.0+21AF26D3----117B344092BD",
+	}
+
+	for _, s := range valid {
+		v, err := Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q) falhou: %v", s, err)
+			continue
+		}
+		if v.String() != s {
+			t.Errorf("Parse(%q).String() = %q", s, v.String())
+		}
+	}
+
+	invalid := []string{"", "1", "1.2", "1.2.3.4", "v1.2.3", "01.2.3", "1.2.3-01", "1.2.3-", "1.2.3+", "1.2.3-a..b", "1.2.3+a_b"}
+	for _, s := range invalid {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) deveria falhar", s)
+		}
+	}
+}
+
+func TestCompareOrdering(t *testing.T) {
+	// Ordem de precedência do exemplo da especificação semver.org
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	versions := make([]*Version, len(ordered))
+	for i := range ordered {
+		// Insere em ordem inversa para que a ordenação tenha efeito
+		v, err := Parse(ordered[len(ordered)-1-i])
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		versions[i] = v
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Compare(versions[j]) < 0 })
+
+	for i, v := range versions {
+		if v.String() != ordered[i] {
+			t.Errorf("Posição %d: esperava %s, obteve %s", i, ordered[i], v)
+		}
+	}
+
+	a, _ := Parse("1.0.0+build.1")
+	b, _ := Parse("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Error("Metadados de build não devem afetar a precedência")
+	}
+}
+
+func TestBumpWithPreid(t *testing.T) {
+	testCases := []struct {
+		current     string
+		releaseType string
+		preid       string
+		expected    string
+	}{
+		{"1.2.3", "prerelease", "rc", "1.2.4-rc.0"},
+		{"1.2.4-rc.0", "prerelease", "rc", "1.2.4-rc.1"},
+		{"1.2.4-beta.3", "prerelease", "rc", "1.2.4-rc.0"},
+		{"1.2.3", "preminor", "", "1.3.0-0"},
+		{"1.2.3-alpha", "prerelease", "alpha", "1.2.3-alpha.0"},
+	}
+
+	for _, tc := range testCases {
+		v, _ := Parse(tc.current)
+		next, err := v.Bump(tc.releaseType, tc.preid)
+		if err != nil {
+			t.Errorf("Bump(%s, %s, %s) falhou: %v", tc.current, tc.releaseType, tc.preid, err)
+			continue
+		}
+		if next.String() != tc.expected {
+			t.Errorf("Bump(%s, %s, %s): esperava %s, obteve %s", tc.current, tc.releaseType, tc.preid, tc.expected, next)
+		}
+	}
+}